@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// runOptions are the settings shared by every directory conversion of a run.
+type runOptions struct {
+	Convert        convertOptions
+	Sync           syncOptions
+	StrictExamples bool
+}
+
+// directoryJob is one raw docs directory converted into one destination.
+type directoryJob struct {
+	Version    string // matrix version, "" for a single conversion
+	SrcDir     string
+	DstDir     string
+	SchemaPath string // optional schema export
+	CLINav     string // optional CLI nav fragment, requires SplitCLI
+}
+
+// versionPlaceholder is replaced by the version in matrix destination paths.
+const versionPlaceholder = "{version}"
+
+// convertDirectory converts job.SrcDir into job.DstDir, writing the sync report
+// to w and example problems to errW, and returns the number of files planned.
+func convertDirectory(w, errW io.Writer, job directoryJob, run runOptions) (int, error) {
+	plan, err := planDirectory(w, job.SrcDir, job.DstDir, run.Convert)
+	if err != nil {
+		return 0, err
+	}
+
+	if job.SchemaPath != "" || run.Convert.IndexPages {
+		schema, err := buildSchema(job.SrcDir, versionFromPath(job.DstDir))
+		if err != nil {
+			return 0, fmt.Errorf("building schema: %w", err)
+		}
+		if run.Convert.IndexPages {
+			plan = append(plan, planIndexPages(schema, job.DstDir, run.Convert)...)
+		}
+		if job.SchemaPath != "" {
+			data, err := marshalSchema(schema)
+			if err != nil {
+				return 0, fmt.Errorf("encoding schema: %w", err)
+			}
+			plan = append(plan, plannedFile{Name: relativeName(job.DstDir, job.SchemaPath), Path: job.SchemaPath, Content: data})
+		}
+	}
+
+	referenceDir := filepath.Dir(filepath.Clean(job.DstDir))
+	if job.CLINav != "" {
+		plan = append(plan, plannedFile{
+			Name:    relativeName(job.DstDir, job.CLINav),
+			Path:    job.CLINav,
+			Content: cliNavFragment(plan, navFolder(referenceDir)),
+		})
+	}
+
+	existing, err := existingOutputs(job.DstDir)
+	if err != nil {
+		return 0, fmt.Errorf("scanning existing output: %w", err)
+	}
+	if run.Convert.SplitCLI {
+		// Command pages of an earlier run that no longer exist are stale too.
+		cliPages, err := existingOutputs(filepath.Join(referenceDir, "cli"))
+		if err != nil {
+			return 0, fmt.Errorf("scanning existing output: %w", err)
+		}
+		for path, name := range cliPages {
+			existing[path] = "../cli/" + name
+		}
+	}
+
+	if !checkExamples(errW, plan) && run.StrictExamples {
+		return 0, errors.New("invalid YAML examples, no files were written")
+	}
+
+	changes, err := diffPlan(plan, existing)
+	if err != nil {
+		return 0, fmt.Errorf("comparing with existing output: %w", err)
+	}
+	if err := applyChanges(w, changes, run.Sync); err != nil {
+		return 0, err
+	}
+	return len(plan), nil
+}
+
+// relativeName reports path relative to dir where possible.
+func relativeName(dir, path string) string {
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// readMatrix reads a YAML mapping of versions to raw docs directories, in file
+// order. Relative directories are resolved against the matrix file:
+//
+//	v1.13: docs/v1.13
+//	v1.14: docs/v1.14
+func readMatrix(path string) ([]directoryJob, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: expected a mapping of versions to docs directories", path)
+	}
+
+	var jobs []directoryJob
+	seen := map[string]bool{}
+	m := doc.Content[0]
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		version, dir := m.Content[i].Value, m.Content[i+1].Value
+		if m.Content[i+1].Kind != yaml.ScalarNode || dir == "" {
+			return nil, fmt.Errorf("%s:%d: %s must map to a docs directory", path, m.Content[i].Line, version)
+		}
+		if seen[version] {
+			return nil, fmt.Errorf("%s:%d: duplicate version %s", path, m.Content[i].Line, version)
+		}
+		seen[version] = true
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(path), dir)
+		}
+		jobs = append(jobs, directoryJob{Version: version, SrcDir: dir})
+	}
+	if len(jobs) == 0 {
+		return nil, fmt.Errorf("%s: no versions", path)
+	}
+	return jobs, nil
+}
+
+// expandJob fills the destination paths of a matrix job from the template.
+func expandJob(job directoryJob, template directoryJob) directoryJob {
+	expand := func(s string) string { return strings.ReplaceAll(s, versionPlaceholder, job.Version) }
+	job.DstDir = expand(template.DstDir)
+	job.SchemaPath = expand(template.SchemaPath)
+	job.CLINav = expand(template.CLINav)
+	return job
+}
+
+// runMatrix converts every version of the matrix file, up to parallel at a
+// time. Each version is converted exactly as a single run would; the reports
+// are buffered and printed in matrix order. It returns the exit code.
+func runMatrix(w io.Writer, matrixPath string, template directoryJob, parallel int, run runOptions) int {
+	for _, p := range []string{template.DstDir, template.SchemaPath, template.CLINav} {
+		if p != "" && !strings.Contains(p, versionPlaceholder) {
+			fmt.Fprintf(os.Stderr, "Error: %s must contain %s with --matrix\n", p, versionPlaceholder)
+			return 1
+		}
+	}
+
+	jobs, err := readMatrix(matrixPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading matrix: %v\n", err)
+		return 1
+	}
+
+	type result struct {
+		report    bytes.Buffer
+		converted int
+		err       error
+	}
+	results := make([]result, len(jobs))
+
+	sem := make(chan struct{}, max(parallel, 1))
+	var wg sync.WaitGroup
+	for i := range jobs {
+		jobs[i] = expandJob(jobs[i], template)
+		wg.Add(1)
+		go func(job directoryJob, r *result) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if info, err := os.Stat(job.SrcDir); err != nil || !info.IsDir() {
+				r.err = fmt.Errorf("%s is not a docs directory", job.SrcDir)
+				return
+			}
+			r.converted, r.err = convertDirectory(&r.report, &r.report, job, run)
+		}(jobs[i], &results[i])
+	}
+	wg.Wait()
+
+	failed := 0
+	for i, job := range jobs {
+		r := &results[i]
+		fmt.Fprintf(w, "=== %s: %s -> %s\n", job.Version, job.SrcDir, job.DstDir)
+		_, _ = r.report.WriteTo(w)
+		if r.err != nil {
+			failed++
+			fmt.Fprintf(w, "Error: %v\n", r.err)
+			continue
+		}
+		fmt.Fprintf(w, "Converted files: %d\n", r.converted)
+	}
+
+	fmt.Fprintf(w, "Matrix: %d converted, %d failed\n", len(jobs)-failed, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// readTree returns every file under dir keyed by its relative path.
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		files[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+func TestMatrixMatchesSequentialRuns(t *testing.T) {
+	root := t.TempDir()
+	versions := map[string]string{
+		"v1.13": hostnameSource,
+		"v1.14": strings.Replace(hostnameSource, "A static hostname.", "A static hostname for the machine.", 1),
+	}
+	var matrix bytes.Buffer
+	for _, v := range []string{"v1.13", "v1.14"} {
+		src := filepath.Join(root, "raw", v)
+		writeSource(t, src, "network/hostnameconfig.md", versions[v])
+		writeSource(t, src, "v1alpha1/config.md", configSource)
+		writeSource(t, src, "cli.md", cliSource)
+		matrix.WriteString(v + ": " + src + "\n")
+	}
+	matrixPath := filepath.Join(root, "matrix.yaml")
+	if err := os.WriteFile(matrixPath, matrix.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := runOptions{Convert: convertOptions{SplitCLI: true}}
+
+	// Sequential runs, one version at a time.
+	seqDir := filepath.Join(root, "sequential")
+	for _, v := range []string{"v1.13", "v1.14"} {
+		job := directoryJob{
+			SrcDir:     filepath.Join(root, "raw", v),
+			DstDir:     filepath.Join(seqDir, v, "reference", "configuration"),
+			SchemaPath: filepath.Join(seqDir, v, "reference", "configuration", "schema.json"),
+		}
+		if _, err := convertDirectory(io.Discard, io.Discard, job, run); err != nil {
+			t.Fatalf("convertDirectory %s: %v", v, err)
+		}
+	}
+
+	matrixDir := filepath.Join(root, "matrix")
+	template := directoryJob{
+		DstDir:     filepath.Join(matrixDir, "{version}", "reference", "configuration"),
+		SchemaPath: filepath.Join(matrixDir, "{version}", "reference", "configuration", "schema.json"),
+	}
+	var out bytes.Buffer
+	if code := runMatrix(&out, matrixPath, template, 2, run); code != 0 {
+		t.Fatalf("runMatrix exited %d:\n%s", code, out.String())
+	}
+
+	seq, par := readTree(t, seqDir), readTree(t, matrixDir)
+	if len(seq) == 0 || len(seq) != len(par) {
+		t.Fatalf("got %d files from the matrix, want %d", len(par), len(seq))
+	}
+	for name, content := range seq {
+		if par[name] != content {
+			t.Errorf("%s differs between matrix and sequential runs", name)
+		}
+	}
+
+	report := out.String()
+	if i, j := strings.Index(report, "=== v1.13:"), strings.Index(report, "=== v1.14:"); i < 0 || j < i {
+		t.Errorf("reports should be printed in matrix order:\n%s", report)
+	}
+	if !strings.Contains(report, "Matrix: 2 converted, 0 failed") {
+		t.Errorf("missing matrix summary:\n%s", report)
+	}
+}
+
+func TestMatrixReportsFailedVersions(t *testing.T) {
+	root := t.TempDir()
+	writeSource(t, filepath.Join(root, "raw"), "network/hostnameconfig.md", hostnameSource)
+	matrixPath := filepath.Join(root, "matrix.yaml")
+	matrix := "v1.13: " + filepath.Join(root, "missing") + "\nv1.14: " + filepath.Join(root, "raw") + "\n"
+	if err := os.WriteFile(matrixPath, []byte(matrix), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	template := directoryJob{DstDir: filepath.Join(root, "out", "{version}")}
+	if code := runMatrix(&out, matrixPath, template, 1, runOptions{}); code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+	for _, s := range []string{"is not a docs directory", "Matrix: 1 converted, 1 failed"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("report missing %q:\n%s", s, out.String())
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "out", "v1.14", "network", "hostnameconfig.mdx")); err != nil {
+		t.Errorf("the remaining version should still be converted: %v", err)
+	}
+}
+
+func TestReadMatrixRejectsDuplicates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "matrix.yaml")
+	if err := os.WriteFile(path, []byte("v1.13: a\nv1.13: b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readMatrix(path); err == nil || !strings.Contains(err.Error(), "duplicate version v1.13") {
+		t.Errorf("err = %v, want duplicate version error", err)
+	}
+}
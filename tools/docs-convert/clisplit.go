@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// A command section of the CLI reference: "## talosctl etcd members".
+	cliHeadingRe = regexp.MustCompile(`^## (talosctl(?: .*)?)$`)
+	// An intra-CLI link: "[talosctl etcd](#talosctl-etcd)".
+	cliLinkRe = regexp.MustCompile(`\]\(#(talosctl[a-z0-9-]*)\)`)
+)
+
+// cliSection is one "## talosctl ..." section of the CLI reference.
+type cliSection struct {
+	Command string // e.g. "talosctl etcd members"
+	Group   string // first subcommand, e.g. "etcd"; "" for talosctl itself
+	Lines   []string
+}
+
+// cliAnchor returns the heading anchor Mintlify gives a command section.
+func cliAnchor(command string) string {
+	return strings.ReplaceAll(strings.ToLower(command), " ", "-")
+}
+
+// parseCLISections splits the body of cli.md into its frontmatter and command
+// sections. Lines before the first command heading are dropped.
+func parseCLISections(lines []string) (frontmatter []string, sections []*cliSection) {
+	i := 0
+	if len(lines) > 0 && lines[0] == "---" {
+		for i = 1; i < len(lines) && lines[i] != "---"; i++ {
+		}
+		frontmatter = lines[:min(i+1, len(lines))]
+		i++
+	}
+
+	var current *cliSection
+	inCodeBlock := false
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCodeBlock = !inCodeBlock
+		}
+		if m := cliHeadingRe.FindStringSubmatch(line); m != nil && !inCodeBlock {
+			current = &cliSection{Command: m[1]}
+			if fields := strings.Fields(m[1]); len(fields) > 1 {
+				current.Group = fields[1]
+			}
+			sections = append(sections, current)
+		}
+		if current != nil {
+			current.Lines = append(current.Lines, line)
+		}
+	}
+	return frontmatter, sections
+}
+
+// shortDescription returns the first paragraph line after the heading.
+func (s *cliSection) shortDescription() string {
+	for _, line := range s.Lines[1:] {
+		if line = strings.TrimSpace(line); line != "" {
+			if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "```") {
+				return ""
+			}
+			return line
+		}
+	}
+	return ""
+}
+
+// planCLIPages splits cli.md into an index page at indexPath, holding the
+// talosctl root command, and one page per command group in a cli/ folder next
+// to it. Links between commands are rewritten to point at the page the target
+// command now lives on. Only the frontmatter rules of opts apply.
+func planCLIPages(srcPath, indexPath string, opts convertOptions) ([]plannedFile, error) {
+	lines, err := readLines(srcPath)
+	if err != nil {
+		return nil, err
+	}
+	frontmatter, sections := parseCLISections(lines)
+
+	groups := map[string][]*cliSection{}
+	for _, s := range sections {
+		groups[s.Group] = append(groups[s.Group], s)
+	}
+	var names []string
+	for g := range groups {
+		if g != "" {
+			names = append(names, g)
+		}
+	}
+	sort.Strings(names)
+
+	pageOf := map[string]string{}
+	for _, s := range sections {
+		pageOf[cliAnchor(s.Command)] = s.Group
+	}
+
+	// rewrite points intra-CLI links at the page of their target, relative
+	// to the page of group from.
+	rewrite := func(from string, line string) string {
+		return cliLinkRe.ReplaceAllStringFunc(line, func(link string) string {
+			anchor := cliLinkRe.FindStringSubmatch(link)[1]
+			to, ok := pageOf[anchor]
+			switch {
+			case !ok || to == from:
+				return link
+			case to == "":
+				return "](../cli#" + anchor + ")"
+			case from == "":
+				return "](./cli/" + to + "#" + anchor + ")"
+			default:
+				return "](./" + to + "#" + anchor + ")"
+			}
+		})
+	}
+
+	render := func(group string, head []string, secs []*cliSection, dstPath string) []byte {
+		src := append([]string{}, head...)
+		for _, s := range secs {
+			if len(src) > 0 && src[len(src)-1] != "" {
+				src = append(src, "")
+			}
+			for _, line := range s.Lines {
+				src = append(src, rewrite(group, line))
+			}
+		}
+		var buf bytes.Buffer
+		convertLines(&buf, src, dstPath, convertOptions{Frontmatter: opts.Frontmatter})
+		return buf.Bytes()
+	}
+
+	dir := filepath.Join(filepath.Dir(indexPath), "cli")
+	plan := []plannedFile{{
+		Name:    filepath.ToSlash(filepath.Join("..", "cli.mdx")),
+		Path:    indexPath,
+		Content: render("", frontmatter, groups[""], indexPath),
+	}}
+
+	for _, g := range names {
+		secs := groups[g]
+		// The group command itself comes first, its subcommands after it.
+		sort.SliceStable(secs, func(i, j int) bool {
+			return secs[i].Command == "talosctl "+g && secs[j].Command != "talosctl "+g
+		})
+
+		description := secs[0].shortDescription()
+		if secs[0].Command != "talosctl "+g || description == "" {
+			description = "talosctl " + g + " commands."
+		}
+		head := []string{
+			"---",
+			"description: " + yamlScalar(description),
+			"title: talosctl " + g,
+			"---",
+		}
+
+		dstPath := filepath.Join(dir, g+".mdx")
+		plan = append(plan, plannedFile{
+			Name:    filepath.ToSlash(filepath.Join("..", "cli", g+".mdx")),
+			Path:    dstPath,
+			Content: render(g, head, secs, dstPath),
+		})
+	}
+	return plan, nil
+}
+
+// yamlScalar quotes s if it would not survive as a plain YAML scalar.
+func yamlScalar(s string) string {
+	if strings.Contains(s, ": ") || strings.HasSuffix(s, ":") ||
+		strings.ContainsAny(s, "#'\"{}[]&*!|>%@`") || strings.HasPrefix(s, "-") {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
+
+// cliNavFragment renders the split CLI pages as a navigation group in the
+// format of the talos-*.yaml files. Paths are relative to the folder holding
+// cli.mdx, which is the "reference" group folder of a version.
+func cliNavFragment(plan []plannedFile, navFolder string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintln(&b, "# Generated by docs-convert --split-cli. Replace the \"cli.mdx\" entry of the")
+	fmt.Fprintf(&b, "# group with folder %q with this group.\n", navFolder)
+	fmt.Fprintln(&b, "- group: \"CLI\"")
+	fmt.Fprintln(&b, "  pages:")
+	for _, p := range plan {
+		if rel, ok := strings.CutPrefix(p.Name, "../"); ok && (rel == "cli.mdx" || strings.HasPrefix(rel, "cli/")) {
+			fmt.Fprintf(&b, "    - %q\n", rel)
+		}
+	}
+	return b.Bytes()
+}
+
+// navFolder returns the docs-gen folder of a directory under public/, e.g.
+// "talos/v1.14/reference", or the directory itself if it is not under public/.
+func navFolder(dir string) string {
+	dir = filepath.ToSlash(filepath.Clean(dir))
+	if i := strings.LastIndex("/"+dir, "/public/"); i >= 0 {
+		return dir[i+len("public/"):]
+	}
+	return dir
+}
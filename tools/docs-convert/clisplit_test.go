@@ -0,0 +1,91 @@
+package main
+
+import (
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const cliSource = "---\n" +
+	"description: Talosctl CLI tool reference.\n" +
+	"title: CLI\n" +
+	"---\n\n" +
+	"<!-- markdownlint-disable -->\n\n" +
+	"## talosctl apply-config\n\n" +
+	"Apply a new configuration to a node\n\n" +
+	"### SEE ALSO\n\n" +
+	"* [talosctl](#talosctl)\t - A CLI for out-of-band management of Kubernetes nodes created by Talos\n\n" +
+	"## talosctl etcd members\n\n" +
+	"List etcd members\n\n" +
+	"### SEE ALSO\n\n" +
+	"* [talosctl etcd](#talosctl-etcd)\t - Manage etcd\n\n" +
+	"## talosctl etcd\n\n" +
+	"Manage etcd\n\n" +
+	"### SEE ALSO\n\n" +
+	"* [talosctl](#talosctl)\t - A CLI for out-of-band management of Kubernetes nodes created by Talos\n" +
+	"* [talosctl etcd members](#talosctl-etcd-members)\t - List etcd members\n\n" +
+	"## talosctl\n\n" +
+	"A CLI for out-of-band management of Kubernetes nodes created by Talos\n\n" +
+	"### SEE ALSO\n\n" +
+	"* [talosctl apply-config](#talosctl-apply-config)\t - Apply a new configuration to a node\n" +
+	"* [talosctl etcd](#talosctl-etcd)\t - Manage etcd\n"
+
+func TestSplitCLI(t *testing.T) {
+	srcDir := t.TempDir()
+	referenceDir := filepath.Join(t.TempDir(), "reference")
+	writeSource(t, srcDir, "cli.md", cliSource)
+
+	plan, err := planDirectory(io.Discard, srcDir, filepath.Join(referenceDir, "configuration"), convertOptions{SplitCLI: true})
+	if err != nil {
+		t.Fatalf("planDirectory: %v", err)
+	}
+
+	pages := map[string]string{}
+	for _, p := range plan {
+		pages[p.Name] = string(p.Content)
+	}
+	if len(pages) != 3 {
+		t.Fatalf("got pages %v, want the index and two group pages", plan)
+	}
+
+	index := pages["../cli.mdx"]
+	for _, s := range []string{
+		"title: talosctl\n",
+		"## talosctl\n",
+		"[talosctl etcd](./cli/etcd#talosctl-etcd)",
+		"[talosctl apply-config](./cli/apply-config#talosctl-apply-config)",
+	} {
+		if !strings.Contains(index, s) {
+			t.Errorf("index missing %q:\n%s", s, index)
+		}
+	}
+	if strings.Contains(index, "## talosctl etcd") {
+		t.Errorf("index should not contain group commands:\n%s", index)
+	}
+
+	etcd := pages["../cli/etcd.mdx"]
+	for _, s := range []string{
+		"description: Manage etcd\n",
+		"title: talosctl etcd\n",
+		"[talosctl](../cli#talosctl)",
+		"[talosctl etcd members](#talosctl-etcd-members)",
+	} {
+		if !strings.Contains(etcd, s) {
+			t.Errorf("etcd page missing %q:\n%s", s, etcd)
+		}
+	}
+	if strings.Index(etcd, "## talosctl etcd\n") > strings.Index(etcd, "## talosctl etcd members") {
+		t.Errorf("group command should come before its subcommands:\n%s", etcd)
+	}
+
+	nav := string(cliNavFragment(plan, navFolder(filepath.Join("docs", "public", "talos", "v1.14", "reference"))))
+	for _, s := range []string{
+		`group with folder "talos/v1.14/reference"`,
+		"    - \"cli.mdx\"\n    - \"cli/apply-config.mdx\"\n    - \"cli/etcd.mdx\"\n",
+	} {
+		if !strings.Contains(nav, s) {
+			t.Errorf("nav fragment missing %q:\n%s", s, nav)
+		}
+	}
+}
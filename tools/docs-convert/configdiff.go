@@ -0,0 +1,390 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// fieldChange is one difference between the same document in two versions.
+type fieldChange struct {
+	Kind    string // "added", "removed", "renamed", "deprecated", "values", "type"
+	Old     *schemaField
+	New     *schemaField
+	Details string
+}
+
+// documentChanges groups the field changes of one document kind.
+type documentChanges struct {
+	Kind    string
+	Old     *schemaDocument
+	New     *schemaDocument
+	Changes []fieldChange
+}
+
+// versionDiff is the difference between two schema exports.
+type versionDiff struct {
+	OldVersion, NewVersion string
+	Added, Removed         []schemaDocument
+	Changed                []documentChanges
+}
+
+// runConfigDiff implements the config-diff subcommand and returns the exit
+// code.
+func runConfigDiff(args []string) int {
+	fset := flag.NewFlagSet("config-diff", flag.ExitOnError)
+	out := fset.String("out", "", "write the MDX page to this file instead of stdout")
+	oldVersion := fset.String("old-version", "", "name of the old version (default: taken from the schema or path)")
+	newVersion := fset.String("new-version", "", "name of the new version (default: taken from the schema or path)")
+	oldBase := fset.String("old-base", "", "URL path of the old version's configuration reference (default /talos/<old version>/reference/configuration)")
+	newBase := fset.String("new-base", "", "URL path of the new version's configuration reference (default /talos/<new version>/reference/configuration)")
+	rulesPath := fset.String("frontmatter-rules", "", "YAML file of frontmatter rules applied to the page (default: built-in rules)")
+	stableVersion := fset.String("stable-version", "", "latest stable version, substituted for {stable} in the frontmatter rules")
+	fset.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: convert-docs config-diff [flags] <old> <new>")
+		fmt.Fprintln(os.Stderr, "  <old> and <new> are schema exports (schema.json), converted reference")
+		fmt.Fprintln(os.Stderr, "  directories containing one, or raw `talosctl docs` directories.")
+		fset.PrintDefaults()
+	}
+	_ = fset.Parse(args)
+
+	if fset.NArg() != 2 {
+		fset.Usage()
+		return 1
+	}
+
+	rules, err := loadFrontmatterRules(*rulesPath, *stableVersion)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading frontmatter rules: %v\n", err)
+		return 1
+	}
+
+	oldSchema, err := loadSchema(fset.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", fset.Arg(0), err)
+		return 1
+	}
+	newSchema, err := loadSchema(fset.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading %s: %v\n", fset.Arg(1), err)
+		return 1
+	}
+
+	if *oldVersion != "" {
+		oldSchema.Version = *oldVersion
+	}
+	if *newVersion != "" {
+		newSchema.Version = *newVersion
+	}
+	if *oldBase == "" {
+		*oldBase = referenceBase(oldSchema.Version)
+	}
+	if *newBase == "" {
+		*newBase = referenceBase(newSchema.Version)
+	}
+
+	page := renderVersionDiff(diffSchemas(oldSchema, newSchema), *oldBase, *newBase, *out, rules)
+
+	if *out == "" {
+		_, _ = os.Stdout.Write(page)
+		return 0
+	}
+	changes, err := diffPlan([]plannedFile{{Name: filepath.Base(*out), Path: *out, Content: page}}, nil)
+	if err == nil {
+		err = applyChanges(os.Stdout, changes, syncOptions{})
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", *out, err)
+		return 1
+	}
+	return 0
+}
+
+// loadSchema reads a schema export, the schema.json inside a converted
+// reference directory, or builds one from a raw `talosctl docs` directory.
+func loadSchema(p string) (*configSchema, error) {
+	info, err := os.Stat(p)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		candidate := filepath.Join(p, "schema.json")
+		if _, err := os.Stat(candidate); errors.Is(err, fs.ErrNotExist) {
+			schema, err := buildSchema(p, versionFromPath(p))
+			if err == nil && len(schema.Documents) == 0 {
+				err = fmt.Errorf("no schema.json or `talosctl docs` configuration pages found in %s; converted MDX has no schema, so convert that version again with --schema first", p)
+			}
+			return schema, err
+		}
+		p = candidate
+	}
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	var schema configSchema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, err
+	}
+	if schema.Version == "" {
+		schema.Version = versionFromPath(p)
+	}
+	return &schema, nil
+}
+
+// referenceBase is the default URL path of a version's configuration reference.
+func referenceBase(version string) string {
+	if version == "" {
+		return "."
+	}
+	return "/talos/" + version + "/reference/configuration"
+}
+
+// diffSchemas compares two schema exports document by document, matching
+// documents by kind and fields by path.
+func diffSchemas(oldSchema, newSchema *configSchema) versionDiff {
+	d := versionDiff{OldVersion: oldSchema.Version, NewVersion: newSchema.Version}
+
+	oldDocs := map[string]*schemaDocument{}
+	for i := range oldSchema.Documents {
+		oldDocs[oldSchema.Documents[i].Kind] = &oldSchema.Documents[i]
+	}
+	newDocs := map[string]*schemaDocument{}
+	for i := range newSchema.Documents {
+		newDocs[newSchema.Documents[i].Kind] = &newSchema.Documents[i]
+	}
+
+	for _, nd := range newSchema.Documents {
+		od, ok := oldDocs[nd.Kind]
+		if !ok {
+			d.Added = append(d.Added, nd)
+			continue
+		}
+		if changes := diffFields(od, newDocs[nd.Kind]); len(changes) > 0 {
+			d.Changed = append(d.Changed, documentChanges{Kind: nd.Kind, Old: od, New: newDocs[nd.Kind], Changes: changes})
+		}
+	}
+	for _, od := range oldSchema.Documents {
+		if _, ok := newDocs[od.Kind]; !ok {
+			d.Removed = append(d.Removed, od)
+		}
+	}
+
+	sort.Slice(d.Added, func(i, j int) bool { return d.Added[i].Kind < d.Added[j].Kind })
+	sort.Slice(d.Removed, func(i, j int) bool { return d.Removed[i].Kind < d.Removed[j].Kind })
+	sort.Slice(d.Changed, func(i, j int) bool { return d.Changed[i].Kind < d.Changed[j].Kind })
+	return d
+}
+
+// diffFields lists the field changes between two versions of a document.
+// A removed and an added field under the same parent with the same type and
+// description are reported as a rename.
+func diffFields(od, nd *schemaDocument) []fieldChange {
+	oldFields := map[string]*schemaField{}
+	for i := range od.Fields {
+		oldFields[od.Fields[i].Path] = &od.Fields[i]
+	}
+	newFields := map[string]*schemaField{}
+	for i := range nd.Fields {
+		newFields[nd.Fields[i].Path] = &nd.Fields[i]
+	}
+
+	var changes, addedFields []fieldChange
+	for i := range nd.Fields {
+		nf := &nd.Fields[i]
+		of, ok := oldFields[nf.Path]
+		if !ok {
+			addedFields = append(addedFields, fieldChange{Kind: "added", New: nf})
+			continue
+		}
+		if nf.Deprecated && !of.Deprecated {
+			changes = append(changes, fieldChange{Kind: "deprecated", Old: of, New: nf, Details: nf.Deprecation})
+		}
+		if of.Type != nf.Type {
+			changes = append(changes, fieldChange{Kind: "type", Old: of, New: nf,
+				Details: fmt.Sprintf("`%s` → `%s`", of.Type, nf.Type)})
+		}
+		if !slices.Equal(of.Values, nf.Values) {
+			changes = append(changes, fieldChange{Kind: "values", Old: of, New: nf, Details: valuesDetails(of.Values, nf.Values)})
+		}
+	}
+
+	var removedFields []*schemaField
+	for i := range od.Fields {
+		if _, ok := newFields[od.Fields[i].Path]; !ok {
+			removedFields = append(removedFields, &od.Fields[i])
+		}
+	}
+
+	renamedFrom := map[*schemaField]bool{}
+	for i, a := range addedFields {
+		for _, of := range removedFields {
+			if renamedFrom[of] || !isRename(of, a.New) {
+				continue
+			}
+			renamedFrom[of] = true
+			addedFields[i] = fieldChange{Kind: "renamed", Old: of, New: a.New,
+				Details: fmt.Sprintf("`%s` → `%s`", of.Path, a.New.Path)}
+			break
+		}
+	}
+	changes = append(changes, addedFields...)
+	for _, of := range removedFields {
+		if !renamedFrom[of] {
+			changes = append(changes, fieldChange{Kind: "removed", Old: of})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changePath(changes[i]) < changePath(changes[j]) })
+	return changes
+}
+
+// isRename reports whether an added field looks like a removed one renamed.
+func isRename(of, nf *schemaField) bool {
+	oldParent, _ := splitFieldPath(of.Path)
+	newParent, _ := splitFieldPath(nf.Path)
+	return oldParent == newParent && of.Type == nf.Type &&
+		of.Description != "" && of.Description == nf.Description
+}
+
+// splitFieldPath splits "machine.install.disk" into "machine.install" and "disk".
+func splitFieldPath(p string) (parent, name string) {
+	if i := strings.LastIndex(p, "."); i >= 0 {
+		return p[:i], p[i+1:]
+	}
+	return "", p
+}
+
+func changePath(c fieldChange) string {
+	if c.New != nil {
+		return c.New.Path
+	}
+	return c.Old.Path
+}
+
+// valuesDetails describes how the allowed values of a field changed.
+func valuesDetails(oldValues, newValues []string) string {
+	var added, removed []string
+	for _, v := range newValues {
+		if !slices.Contains(oldValues, v) {
+			added = append(added, "`"+v+"`")
+		}
+	}
+	for _, v := range oldValues {
+		if !slices.Contains(newValues, v) {
+			removed = append(removed, "`"+v+"`")
+		}
+	}
+	var parts []string
+	if len(added) > 0 {
+		parts = append(parts, "added "+strings.Join(added, ", "))
+	}
+	if len(removed) > 0 {
+		parts = append(parts, "removed "+strings.Join(removed, ", "))
+	}
+	if len(parts) == 0 {
+		return "reordered"
+	}
+	return strings.Join(parts, "; ")
+}
+
+var changeLabels = map[string]string{
+	"added":      "Added",
+	"removed":    "Removed",
+	"renamed":    "Renamed",
+	"deprecated": "Deprecated",
+	"values":     "Allowed values changed",
+	"type":       "Type changed",
+}
+
+// renderVersionDiff renders the difference between two versions as an MDX
+// page, linking every field to its anchor in the version it exists in. The
+// frontmatter rules are matched against dstPath.
+func renderVersionDiff(d versionDiff, oldBase, newBase, dstPath string, rules *frontmatterRules) []byte {
+	oldName, newName := d.OldVersion, d.NewVersion
+	if oldName == "" {
+		oldName = "old"
+	}
+	if newName == "" {
+		newName = "new"
+	}
+
+	var b bytes.Buffer
+	writeGeneratedFrontmatter(&b, dstPath,
+		fmt.Sprintf("Configuration changes from %s to %s", oldName, newName),
+		fmt.Sprintf("Configuration documents and fields added, removed, renamed or deprecated between %s and %s.", oldName, newName),
+		rules)
+
+	if len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0 {
+		fmt.Fprintf(&b, "\nThe configuration documents did not change between %s and %s.\n", oldName, newName)
+		return b.Bytes()
+	}
+
+	if len(d.Added) > 0 {
+		fmt.Fprintln(&b, "\n## New documents")
+		fmt.Fprintln(&b)
+		for _, doc := range d.Added {
+			fmt.Fprintf(&b, "- [`%s`](%s)\n", doc.Kind, referenceLink(newBase, doc.Page))
+		}
+	}
+
+	if len(d.Removed) > 0 {
+		fmt.Fprintln(&b, "\n## Removed documents")
+		fmt.Fprintln(&b)
+		for _, doc := range d.Removed {
+			fmt.Fprintf(&b, "- [`%s`](%s)\n", doc.Kind, referenceLink(oldBase, doc.Page))
+		}
+	}
+
+	for _, dc := range d.Changed {
+		fmt.Fprintf(&b, "\n## %s\n\n", dc.Kind)
+		fmt.Fprintf(&b, "Reference: [%s](%s), [%s](%s)\n\n",
+			oldName, referenceLink(oldBase, dc.Old.Page), newName, referenceLink(newBase, dc.New.Page))
+		fmt.Fprintln(&b, "| Change | Field | Details |")
+		fmt.Fprintln(&b, "| --- | --- | --- |")
+		for _, c := range dc.Changes {
+			var field string
+			switch {
+			case c.New != nil && c.Old != nil && c.Kind != "renamed":
+				// A field of both versions links to both.
+				field = fmt.Sprintf("[`%s`](%s) ([%s](%s))", c.New.Path, referenceLink(newBase, c.New.Anchor),
+					oldName, referenceLink(oldBase, c.Old.Anchor))
+			case c.New != nil:
+				field = fmt.Sprintf("[`%s`](%s)", c.New.Path, referenceLink(newBase, c.New.Anchor))
+			default:
+				field = fmt.Sprintf("[`%s`](%s)", c.Old.Path, referenceLink(oldBase, c.Old.Anchor))
+			}
+			if c.Kind == "renamed" {
+				field = fmt.Sprintf("[`%s`](%s) → %s", c.Old.Path, referenceLink(oldBase, c.Old.Anchor), field)
+				c.Details = ""
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", changeLabels[c.Kind], field, tableCell(c.Details))
+		}
+	}
+
+	return b.Bytes()
+}
+
+// referenceLink joins a reference base URL path and a page anchor.
+func referenceLink(base, anchor string) string {
+	if strings.HasPrefix(base, "/") {
+		return path.Join(base, anchor)
+	}
+	return strings.TrimSuffix(base, "/") + "/" + anchor
+}
+
+// tableCell makes free text safe for a Markdown table cell in MDX.
+func tableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return escapeAngleBracketPlaceholders(s)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDiffSchemas(t *testing.T) {
+	oldSchema := &configSchema{Version: "v1.13", Documents: []schemaDocument{
+		{Kind: "HostnameConfig", Page: "network/hostnameconfig", Fields: []schemaField{
+			{Path: "auto", Type: "AutoHostnameKind", Values: []string{"stable"}, Anchor: "network/hostnameconfig"},
+			{Path: "hostname", Type: "string", Description: "A static hostname.", Anchor: "network/hostnameconfig"},
+			{Path: "domain", Type: "string", Anchor: "network/hostnameconfig"},
+		}},
+		{Kind: "LegacyConfig", Page: "runtime/legacyconfig"},
+	}}
+	newSchema := &configSchema{Version: "v1.14", Documents: []schemaDocument{
+		{Kind: "HostnameConfig", Page: "network/hostnameconfig", Fields: []schemaField{
+			{Path: "auto", Type: "AutoHostnameKind", Values: []string{"stable", "off"}, Anchor: "network/hostnameconfig",
+				Deprecated: true, Deprecation: "Deprecated: use name instead."},
+			{Path: "name", Type: "string", Description: "A static hostname.", Anchor: "network/hostnameconfig"},
+			{Path: "ttl", Type: "Duration", Anchor: "network/hostnameconfig"},
+		}},
+		{Kind: "KubeletConfig", Page: "kubernetes/kubeletconfig"},
+	}}
+
+	d := diffSchemas(oldSchema, newSchema)
+	if len(d.Added) != 1 || d.Added[0].Kind != "KubeletConfig" {
+		t.Errorf("added documents = %+v, want KubeletConfig", d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].Kind != "LegacyConfig" {
+		t.Errorf("removed documents = %+v, want LegacyConfig", d.Removed)
+	}
+	if len(d.Changed) != 1 {
+		t.Fatalf("got %d changed documents, want 1", len(d.Changed))
+	}
+
+	var got []string
+	for _, c := range d.Changed[0].Changes {
+		got = append(got, c.Kind+" "+changePath(c))
+	}
+	want := []string{"deprecated auto", "values auto", "removed domain", "renamed name", "added ttl"}
+	if strings.Join(got, ", ") != strings.Join(want, ", ") {
+		t.Errorf("changes = %v, want %v", got, want)
+	}
+
+	page := string(renderVersionDiff(d, referenceBase("v1.13"), referenceBase("v1.14"), "", convertOptions{}.frontmatter()))
+	for _, s := range []string{
+		"title: Configuration changes from v1.13 to v1.14",
+		"- [`KubeletConfig`](/talos/v1.14/reference/configuration/kubernetes/kubeletconfig)",
+		"- [`LegacyConfig`](/talos/v1.13/reference/configuration/runtime/legacyconfig)",
+		"## HostnameConfig",
+		"| Allowed values changed | [`auto`](/talos/v1.14/reference/configuration/network/hostnameconfig) ([v1.13](/talos/v1.13/reference/configuration/network/hostnameconfig)) | added `off` |",
+		"| Renamed | [`hostname`](/talos/v1.13/reference/configuration/network/hostnameconfig) → [`name`](/talos/v1.14/reference/configuration/network/hostnameconfig) |",
+		"| Removed | [`domain`](/talos/v1.13/reference/configuration/network/hostnameconfig) |",
+	} {
+		if !strings.Contains(page, s) {
+			t.Errorf("page missing %q:\n%s", s, page)
+		}
+	}
+}
+
+func TestLoadSchemaFromDirectories(t *testing.T) {
+	rawDir := t.TempDir()
+	writeSource(t, rawDir, "network/hostnameconfig.md", hostnameSource)
+
+	schema, err := loadSchema(rawDir)
+	if err != nil {
+		t.Fatalf("loadSchema(raw dir): %v", err)
+	}
+	if len(schema.Documents) != 1 || schema.Documents[0].Kind != "HostnameConfig" {
+		t.Errorf("unexpected documents from raw dir: %+v", schema.Documents)
+	}
+
+	convertedDir := filepath.Join(t.TempDir(), "v1.14", "configuration")
+	if err := os.MkdirAll(convertedDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	data, err := marshalSchema(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(convertedDir, "schema.json"), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	schema, err = loadSchema(convertedDir)
+	if err != nil {
+		t.Fatalf("loadSchema(converted dir): %v", err)
+	}
+	if schema.Version != "v1.14" || len(schema.Documents) != 1 {
+		t.Errorf("unexpected schema from converted dir: version=%q documents=%d", schema.Version, len(schema.Documents))
+	}
+
+	if _, err := loadSchema(t.TempDir()); err == nil {
+		t.Error("expected an error for a directory without schema.json or source pages")
+	}
+}
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// exampleProblem is one YAML example in a generated page that does not parse
+// or does not fit the document it illustrates.
+type exampleProblem struct {
+	Page    string // page name, as reported by the sync summary
+	Kind    string // document kind, from the page title
+	Field   string // field or section the example belongs to; "" for the document itself
+	Line    int    // line of the page the problem was found on
+	Message string
+}
+
+func (p exampleProblem) String() string {
+	field := p.Field
+	if field == "" {
+		field = "(document)"
+	}
+	return fmt.Sprintf("%s:%d: %s %s: %s", p.Page, p.Line, p.Kind, field, p.Message)
+}
+
+// yamlExample is one ```yaml block found in a generated page.
+type yamlExample struct {
+	Field   string
+	InField bool // belongs to a field rather than to a section
+	Line    int  // line of the opening fence
+	Cell    bool // squashed into a table cell
+	Body    string
+}
+
+var (
+	// A ```yaml block inside a single line, as left by processCellContent.
+	inlineYAMLRe = regexp.MustCompile("```yaml(.*?)```")
+	titleLineRe  = regexp.MustCompile(`^title:\s*(.*?)\s*$`)
+)
+
+// exampleDocumentKeys are top-level keys any document example may use besides
+// the document's own fields.
+var exampleDocumentKeys = []string{"apiVersion", "kind"}
+
+// validateExamples parses every YAML example in a generated configuration page.
+// Each example must be valid YAML; document and section examples may only use
+// the document's top-level fields, and field examples the field itself.
+// Pages without a field reference, such as the CLI reference, are skipped.
+func validateExamples(page string, content []byte) []exampleProblem {
+	lines := strings.Split(string(content), "\n")
+
+	kind := ""
+	i := 0
+	if len(lines) > 0 && lines[0] == "---" {
+		for i = 1; i < len(lines) && lines[i] != "---"; i++ {
+			if m := titleLineRe.FindStringSubmatch(lines[i]); m != nil {
+				kind = strings.Trim(m[1], `'"`)
+			}
+		}
+		i++
+	}
+
+	var examples []yamlExample
+	rootFields := map[string]bool{}
+	section := ""
+	var params []string
+	rowField := ""
+	inRow := false
+
+	fieldPath := func(name string) string {
+		if len(params) > 0 {
+			return params[len(params)-1] + "." + name
+		}
+		if section != "" {
+			return section + "." + name
+		}
+		return name
+	}
+	current := func() (string, bool) {
+		switch {
+		case len(params) > 0:
+			return params[len(params)-1], true
+		case inRow && rowField != "":
+			return fieldPath(rowField), true
+		default:
+			return section, false
+		}
+	}
+
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "```yaml":
+			start := i
+			var body []string
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "```"; i++ {
+				body = append(body, lines[i])
+			}
+			field, inField := current()
+			examples = append(examples, yamlExample{Field: field, InField: inField, Line: start + 1, Body: strings.Join(body, "\n")})
+			continue
+		case headingLineRe.MatchString(line):
+			section = headingLineRe.FindStringSubmatch(line)[1]
+		case paramFieldRe.MatchString(trimmed):
+			name := paramFieldRe.FindStringSubmatch(trimmed)[1]
+			if section == "" && len(params) == 0 {
+				rootFields[name] = true
+			}
+			params = append(params, fieldPath(name))
+		case trimmed == "</ParamField>" && len(params) > 0:
+			params = params[:len(params)-1]
+		case trimmed == "<tr>":
+			inRow, rowField = true, ""
+		case trimmed == "</tr>":
+			inRow = false
+		case inRow && rowField == "" && fieldCellRe.MatchString(line):
+			rowField = fieldCellRe.FindStringSubmatch(line)[1]
+			if section == "" {
+				rootFields[rowField] = true
+			}
+		}
+
+		for _, m := range inlineYAMLRe.FindAllStringSubmatch(line, -1) {
+			field, inField := current()
+			examples = append(examples, yamlExample{Field: field, InField: inField, Line: i + 1, Cell: true, Body: m[1]})
+		}
+	}
+
+	if len(rootFields) == 0 {
+		return nil
+	}
+	for _, k := range exampleDocumentKeys {
+		rootFields[k] = true
+	}
+
+	var problems []exampleProblem
+	for _, ex := range examples {
+		report := func(line int, format string, args ...any) {
+			problems = append(problems, exampleProblem{
+				Page: page, Kind: kind, Field: ex.Field, Line: line,
+				Message: fmt.Sprintf(format, args...),
+			})
+		}
+
+		dec := yaml.NewDecoder(strings.NewReader(ex.Body))
+		for {
+			var node yaml.Node
+			err := dec.Decode(&node)
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				line := ex.Line
+				if !ex.Cell {
+					line += yamlErrorLine(err)
+				}
+				report(line, "invalid YAML: %v", err)
+				break
+			}
+
+			if len(node.Content) == 0 || node.Content[0].Kind != yaml.MappingNode {
+				continue
+			}
+			mapping := node.Content[0]
+
+			// Section examples are written from the document root; field
+			// examples may also start at the field itself.
+			allowed := rootFields
+			if ex.InField {
+				allowed = map[string]bool{fieldName(ex.Field): true}
+				for k := range rootFields {
+					allowed[k] = true
+				}
+			}
+
+			var unknown []string
+			for j := 0; j+1 < len(mapping.Content); j += 2 {
+				key, value := mapping.Content[j], mapping.Content[j+1]
+				if !allowed[key.Value] {
+					unknown = append(unknown, key.Value)
+				}
+				if key.Value == "kind" && kind != "" && value.Value != kind {
+					report(lineOf(ex, key), "kind %q does not match document kind %q", value.Value, kind)
+				}
+			}
+			if len(unknown) > 0 {
+				sort.Strings(unknown)
+				report(lineOf(ex, mapping), "unexpected top-level key(s) %s", strings.Join(unknown, ", "))
+			}
+		}
+	}
+	return problems
+}
+
+// fieldName returns the last element of a field path.
+func fieldName(path string) string {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		return path[i+1:]
+	}
+	return path
+}
+
+// lineOf returns the page line of a node of an example.
+func lineOf(ex yamlExample, n *yaml.Node) int {
+	if ex.Cell {
+		return ex.Line
+	}
+	return ex.Line + n.Line
+}
+
+var yamlLineRe = regexp.MustCompile(`line (\d+)`)
+
+// yamlErrorLine extracts the line number from a yaml.v3 syntax error, or 0.
+func yamlErrorLine(err error) int {
+	if m := yamlLineRe.FindStringSubmatch(err.Error()); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	return 0
+}
+
+// checkExamples validates the examples of every planned page and reports the
+// problems to w. It returns false if any example is invalid.
+func checkExamples(w io.Writer, plan []plannedFile) bool {
+	var problems []exampleProblem
+	for _, p := range plan {
+		if strings.HasSuffix(p.Path, ".mdx") {
+			problems = append(problems, validateExamples(p.Name, p.Content)...)
+		}
+	}
+	if len(problems) == 0 {
+		return true
+	}
+	fmt.Fprintf(w, "Invalid YAML examples: %d\n", len(problems))
+	for _, p := range problems {
+		fmt.Fprintf(w, "  %s\n", p)
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func renderSource(t *testing.T, source string, opts convertOptions) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	convertLines(&buf, strings.Split(source, "\n"), "configuration/v1alpha1/config.mdx", opts)
+	return buf.Bytes()
+}
+
+func TestValidateExamplesAcceptsGeneratedPages(t *testing.T) {
+	for _, opts := range []convertOptions{{}, {ParamFields: true}} {
+		for name, source := range map[string]string{"config": configSource, "hostname": hostnameSource} {
+			if problems := validateExamples(name, renderSource(t, source, opts)); len(problems) > 0 {
+				t.Errorf("%s (param fields %v): unexpected problems %v", name, opts.ParamFields, problems)
+			}
+		}
+	}
+}
+
+func TestValidateExamplesReportsProblems(t *testing.T) {
+	source := strings.Replace(configSource, "version: v1alpha1\nmachine: # ...", "version: v1alpha1\nmachine:\n  type: worker\n token: abc\nclusterName: test", 1)
+	source = strings.Replace(source, "token: 328hom.uqjzh6jnn2eie9oi", "tokens: 328hom.uqjzh6jnn2eie9oi", 1)
+
+	page := renderSource(t, source, convertOptions{ParamFields: true})
+	problems := validateExamples("v1alpha1/config.mdx", page)
+	if len(problems) != 2 {
+		t.Fatalf("got problems %v, want 2", problems)
+	}
+
+	lines := strings.Split(string(page), "\n")
+	if p := problems[0]; p.Field != "" || !strings.Contains(p.Message, "invalid YAML") || !strings.HasPrefix(lines[p.Line-1], "  ") {
+		t.Errorf("unexpected syntax problem %+v (line %q)", p, lines[p.Line-1])
+	}
+	if p := problems[1]; p.Field != "machine.token" || !strings.Contains(p.Message, "tokens") || strings.TrimSpace(lines[p.Line-1]) != "tokens: 328hom.uqjzh6jnn2eie9oi" {
+		t.Errorf("unexpected key problem %+v", p)
+	}
+	if got := problems[1].String(); !strings.HasPrefix(got, "v1alpha1/config.mdx:") || !strings.Contains(got, "Config machine.token: unexpected top-level key(s) tokens") {
+		t.Errorf("unexpected report %q", got)
+	}
+}
+
+func TestValidateExamplesChecksKindAndTableCells(t *testing.T) {
+	page := "---\ntitle: HostnameConfig\n---\n\n" +
+		"```yaml\napiVersion: v1alpha1\nkind: HostnameConf\nauto: stable\n```\n\n" +
+		"<table>\n  <tbody>\n    <tr>\n      <td>`auto`</td>\n" +
+		"      <td>Example: ```yaml auto: off: true ```</td>\n    </tr>\n  </tbody>\n</table>\n"
+
+	problems := validateExamples("network/hostnameconfig.mdx", []byte(page))
+	if len(problems) != 2 {
+		t.Fatalf("got problems %v, want 2", problems)
+	}
+	if p := problems[0]; p.Line != 7 || !strings.Contains(p.Message, `kind "HostnameConf" does not match document kind "HostnameConfig"`) {
+		t.Errorf("unexpected kind problem %+v", p)
+	}
+	if p := problems[1]; p.Line != 15 || p.Field != "auto" || !strings.Contains(p.Message, "invalid YAML") {
+		t.Errorf("unexpected cell problem %+v", p)
+	}
+}
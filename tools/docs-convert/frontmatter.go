@@ -0,0 +1,267 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultFrontmatterRules are used when no rules file is given. They keep the
+// titles of the machine config and CLI reference pages.
+//
+//go:embed frontmatter.yaml
+var defaultFrontmatterRules []byte
+
+var (
+	defaultRulesOnce sync.Once
+	defaultRules     *frontmatterRules
+)
+
+// frontmatter returns the rules selected by opts, or the embedded defaults.
+func (opts convertOptions) frontmatter() *frontmatterRules {
+	if opts.Frontmatter != nil {
+		return opts.Frontmatter
+	}
+	defaultRulesOnce.Do(func() {
+		rules, err := parseFrontmatterRules(defaultFrontmatterRules, "")
+		if err != nil {
+			panic(fmt.Sprintf("embedded frontmatter rules: %v", err))
+		}
+		defaultRules = rules
+	})
+	return defaultRules
+}
+
+// frontmatterRule sets frontmatter and imports for the pages matching a path
+// pattern. Every matching rule applies, in file order; later rules override
+// the fields set by earlier ones.
+type frontmatterRule struct {
+	// Match is a path.Match pattern matched against the trailing segments of
+	// the destination path, e.g. "cli.mdx" or "network/*.mdx".
+	Match         string   `yaml:"match"`
+	Title         string   `yaml:"title"`
+	SidebarTitle  string   `yaml:"sidebarTitle"`
+	Description   string   `yaml:"description"`
+	Canonical     string   `yaml:"canonical"`
+	VersionImport []string `yaml:"versionImport"` // custom-variables.mdx names, suffixed with the version
+	Banner        *bool    `yaml:"banner"`        // import and render <VersionWarningBanner />
+}
+
+// frontmatterRules is a parsed rules file.
+type frontmatterRules struct {
+	Rules  []frontmatterRule `yaml:"rules"`
+	stable string
+}
+
+// pageRules is the outcome of the rules for one page.
+type pageRules struct {
+	Fields        [][2]string // frontmatter keys to set, in order
+	VersionImport []string
+	Banner        bool
+}
+
+// loadFrontmatterRules reads a rules file, or the embedded defaults if path is
+// empty. stable replaces {stable} in the rules.
+func loadFrontmatterRules(path, stable string) (*frontmatterRules, error) {
+	data := defaultFrontmatterRules
+	if path != "" {
+		var err error
+		if data, err = os.ReadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	rules, err := parseFrontmatterRules(data, stable)
+	if err != nil && path != "" {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, err
+}
+
+// parseFrontmatterRules decodes and checks a rules file.
+func parseFrontmatterRules(data []byte, stable string) (*frontmatterRules, error) {
+	rules := &frontmatterRules{stable: stable}
+	if err := yaml.Unmarshal(data, rules); err != nil {
+		return nil, err
+	}
+	for i, r := range rules.Rules {
+		if r.Match == "" {
+			return nil, fmt.Errorf("rule %d has no match pattern", i+1)
+		}
+		if _, err := path.Match(r.Match, ""); err != nil {
+			return nil, fmt.Errorf("rule %d: bad pattern %q: %w", i+1, r.Match, err)
+		}
+		for _, v := range []string{r.Title, r.SidebarTitle, r.Description, r.Canonical} {
+			if strings.Contains(v, "{stable}") && stable == "" {
+				return nil, fmt.Errorf("rule %d (%s) uses {stable}, which requires --stable-version", i+1, r.Match)
+			}
+		}
+	}
+	return rules, nil
+}
+
+// matchesPath reports whether pattern matches the trailing segments of p.
+func matchesPath(pattern, p string) bool {
+	segments := strings.Split(filepath.ToSlash(filepath.Clean(p)), "/")
+	n := strings.Count(pattern, "/") + 1
+	if n > len(segments) {
+		return false
+	}
+	ok, _ := path.Match(pattern, strings.Join(segments[len(segments)-n:], "/"))
+	return ok
+}
+
+// forPage resolves the rules that apply to the page written to dstPath.
+func (rs *frontmatterRules) forPage(dstPath string) pageRules {
+	var pr pageRules
+	if rs == nil {
+		return pr
+	}
+
+	version := versionFromPath(dstPath)
+	pagePath := filepath.ToSlash(strings.TrimSuffix(filepath.Clean(dstPath), filepath.Ext(dstPath)))
+	if version != "" {
+		if _, rest, ok := strings.Cut(pagePath, "/"+version+"/"); ok {
+			pagePath = rest
+		}
+	}
+	expand := strings.NewReplacer(
+		"{version}", version,
+		"{stable}", rs.stable,
+		"{path}", pagePath,
+	).Replace
+
+	set := func(key, value string) {
+		if value == "" {
+			return
+		}
+		value = expand(value)
+		for i := range pr.Fields {
+			if pr.Fields[i][0] == key {
+				pr.Fields[i][1] = value
+				return
+			}
+		}
+		pr.Fields = append(pr.Fields, [2]string{key, value})
+	}
+
+	for _, r := range rs.Rules {
+		if !matchesPath(r.Match, dstPath) {
+			continue
+		}
+		set("title", r.Title)
+		set("sidebarTitle", r.SidebarTitle)
+		set("description", r.Description)
+		set("canonical", r.Canonical)
+		if r.VersionImport != nil {
+			pr.VersionImport = append([]string{}, r.VersionImport...)
+		}
+		if r.Banner != nil {
+			pr.Banner = *r.Banner
+		}
+	}
+
+	// The version suffix of the imports needs a version folder.
+	if version == "" {
+		pr.VersionImport = nil
+	}
+	for i, name := range pr.VersionImport {
+		pr.VersionImport[i] = name + "_" + strings.ReplaceAll(version, ".", "_")
+	}
+	return pr
+}
+
+// applyFrontmatter sets the rule fields in the body of a frontmatter block
+// (the lines between the --- markers). An existing key, including its
+// continuation lines, is replaced in place; new keys are appended.
+func (pr pageRules) applyFrontmatter(body string) string {
+	if len(pr.Fields) == 0 {
+		return body
+	}
+
+	type entry struct {
+		key   string
+		lines []string
+	}
+	var entries []*entry
+	for _, line := range strings.SplitAfter(body, "\n") {
+		if line == "" {
+			continue
+		}
+		key, _, isKey := strings.Cut(line, ":")
+		if isKey && line[0] != ' ' && line[0] != '\t' && line[0] != '#' && !strings.ContainsAny(key, " \t") {
+			entries = append(entries, &entry{key: key})
+		} else if len(entries) == 0 {
+			entries = append(entries, &entry{})
+		}
+		last := entries[len(entries)-1]
+		last.lines = append(last.lines, line)
+	}
+
+	for _, f := range pr.Fields {
+		line := f[0] + ": " + yamlScalar(f[1]) + "\n"
+		found := false
+		for _, e := range entries {
+			if e.key == f[0] {
+				e.lines, found = []string{line}, true
+			}
+		}
+		if !found {
+			entries = append(entries, &entry{key: f[0], lines: []string{line}})
+		}
+	}
+
+	var b strings.Builder
+	for _, e := range entries {
+		for _, line := range e.lines {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+// writeImports writes the imports and the version banner a page needs,
+// after its frontmatter.
+func (pr pageRules) writeImports(w io.Writer) {
+	if !pr.Banner && len(pr.VersionImport) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	if pr.Banner {
+		fmt.Fprintln(w, `import { VersionWarningBanner } from "/snippets/version-warning-banner.jsx"`)
+	}
+	if len(pr.VersionImport) > 0 {
+		fmt.Fprintf(w, "import { %s } from '/snippets/custom-variables.mdx';\n", strings.Join(pr.VersionImport, ", "))
+	}
+	if pr.Banner {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "<VersionWarningBanner />")
+	}
+}
+
+// writeGeneratedFrontmatter writes the frontmatter, the auto-generated
+// comment and the imports of a page docs-convert creates itself.
+func writeGeneratedFrontmatter(b *bytes.Buffer, dstPath, title, description string, rules *frontmatterRules) {
+	pr := rules.forPage(dstPath)
+	fmt.Fprintln(b, "---")
+	b.WriteString(pr.applyFrontmatter(fmt.Sprintf("title: %s\ndescription: %s\n", yamlScalar(title), yamlScalar(description))))
+	fmt.Fprintln(b, "---")
+	writeGeneratedComment(b)
+	pr.writeImports(b)
+}
+
+// writeGeneratedComment writes the note that marks a page as generated.
+func writeGeneratedComment(w io.Writer) {
+	fmt.Fprintln(w, "")
+	fmt.Fprintln(w, "{/*")
+	fmt.Fprintln(w, "This file is automatically generated from source documentation.")
+	fmt.Fprintln(w, "Do not edit manually. For more information, see https://github.com/siderolabs/docs")
+	fmt.Fprintln(w, "*/}")
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+const rulesSource = `rules:
+  - match: "*.mdx"
+    canonical: https://docs.siderolabs.com/talos/{stable}/{path}
+    banner: true
+  - match: "network/*.mdx"
+    sidebarTitle: Network {version}
+    versionImport: [release, version]
+  - match: "network/hostnameconfig.mdx"
+    title: Hostname
+    banner: false
+`
+
+func TestFrontmatterRules(t *testing.T) {
+	rules, err := parseFrontmatterRules([]byte(rulesSource), "v1.13")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	source := "---\ntitle: HostnameConfig\ndescription: |\n    HostnameConfig configures\n    the hostname.\n---\n\n## HostnameConfig\n"
+	var buf bytes.Buffer
+	convertLines(&buf, strings.Split(source, "\n"), "public/talos/v1.14/reference/configuration/network/hostnameconfig.mdx", convertOptions{Frontmatter: rules})
+	want := `---
+title: Hostname
+description: |
+  HostnameConfig configures
+  the hostname.
+canonical: https://docs.siderolabs.com/talos/v1.13/reference/configuration/network/hostnameconfig
+sidebarTitle: Network v1.14
+---
+
+{/*
+This file is automatically generated from source documentation.
+Do not edit manually. For more information, see https://github.com/siderolabs/docs
+*/}
+
+import { release_v1_14, version_v1_14 } from '/snippets/custom-variables.mdx';
+
+## HostnameConfig
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	buf.Reset()
+	convertLines(&buf, strings.Split("---\ntitle: talosctl\n---\n", "\n"), "public/talos/v1.14/reference/cli.mdx", convertOptions{Frontmatter: rules})
+	for _, s := range []string{
+		"canonical: https://docs.siderolabs.com/talos/v1.13/reference/cli\n",
+		"*/}\n\nimport { VersionWarningBanner } from \"/snippets/version-warning-banner.jsx\"\n\n<VersionWarningBanner />\n",
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Errorf("cli.mdx missing %q:\n%s", s, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), "custom-variables") {
+		t.Errorf("cli.mdx should not import version variables:\n%s", buf.String())
+	}
+}
+
+func TestDefaultFrontmatterRules(t *testing.T) {
+	for _, tc := range []struct{ path, title string }{
+		{"out/configuration/v1alpha1/config.mdx", "MachineConfig"},
+		{"out/reference/cli.mdx", "talosctl"},
+		{"out/reference/cli/etcd.mdx", "talosctl etcd"},
+		{"out/configuration/network/hostnameconfig.mdx", "HostnameConfig"},
+	} {
+		var buf bytes.Buffer
+		convertLines(&buf, []string{"---", "title: " + tc.title, "---"}, tc.path, convertOptions{})
+		if !strings.Contains(buf.String(), "\ntitle: "+tc.title+"\n") {
+			t.Errorf("%s: want title %q:\n%s", tc.path, tc.title, buf.String())
+		}
+	}
+
+	var buf bytes.Buffer
+	convertLines(&buf, []string{"---", "title: Config", "---"}, "v1alpha1/config.mdx", convertOptions{})
+	if !strings.Contains(buf.String(), "\ntitle: MachineConfig\n") || strings.Contains(buf.String(), "\ntitle: Config\n") {
+		t.Errorf("config page should be titled MachineConfig:\n%s", buf.String())
+	}
+}
+
+func TestFrontmatterRulesErrors(t *testing.T) {
+	for name, src := range map[string]string{
+		"no match":    "rules:\n  - title: x\n",
+		"bad pattern": "rules:\n  - match: \"[\"\n",
+		"no stable":   "rules:\n  - match: \"*.mdx\"\n    canonical: /talos/{stable}/{path}\n",
+	} {
+		if _, err := parseFrontmatterRules([]byte(src), ""); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"strings"
+)
+
+// mdxElements lists the HTML elements kept as markup in MDX output, with the
+// attributes each may carry. Any other tag is treated as text, such as a
+// placeholder like <node-ip>, and escaped.
+var mdxElements = map[string]map[string]bool{
+	"a":         {"href": true, "id": true, "name": true, "title": true, "target": true, "rel": true},
+	"br":        {},
+	"p":         {},
+	"pre":       {},
+	"code":      {},
+	"details":   {"open": true},
+	"summary":   {},
+	"Accordion": {"title": true},
+	"table":     {},
+	"thead":     {},
+	"tbody":     {},
+	"tr":        {},
+	"td":        {"style": true, "colspan": true, "rowspan": true},
+	"th":        {"style": true, "colspan": true, "rowspan": true},
+}
+
+// inlineKind is the kind of an inlineToken.
+type inlineKind int
+
+const (
+	textToken      inlineKind = iota
+	codeToken                 // code span, including its backticks
+	tagToken                  // start, end or self-closing tag
+	commentToken              // <!-- ... -->
+	shortcodeToken            // Hugo {{< ... >}}
+)
+
+// htmlAttr is one attribute of a tag.
+type htmlAttr struct {
+	Name  string
+	Value string
+}
+
+// inlineToken is a piece of a line of Markdown. Concatenating the Raw text of
+// all tokens gives back the input.
+type inlineToken struct {
+	Kind        inlineKind
+	Raw         string
+	Name        string // tag name
+	Closing     bool   // </name>
+	SelfClosing bool   // <name />
+	Attrs       []htmlAttr
+}
+
+// allowed reports whether a tag token may stay markup in MDX.
+func (t inlineToken) allowed() bool {
+	attrs, ok := mdxElements[t.Name]
+	if !ok || t.Closing && len(t.Attrs) > 0 {
+		return false
+	}
+	for _, a := range t.Attrs {
+		if !attrs[a.Name] {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenizeInline splits s into text, code spans, tags, HTML comments and Hugo
+// shortcodes. A "<" that does not start a well-formed tag, comment or
+// shortcode, and a backtick run without a matching closing run, are text.
+func tokenizeInline(s string) []inlineToken {
+	var tokens []inlineToken
+	textStart := 0
+	emit := func(i int, t inlineToken) {
+		if textStart < i {
+			tokens = append(tokens, inlineToken{Kind: textToken, Raw: s[textStart:i]})
+		}
+		tokens = append(tokens, t)
+		textStart = i + len(t.Raw)
+	}
+
+	for i := 0; i < len(s); {
+		switch {
+		case s[i] == '`':
+			n := backtickRun(s, i)
+			if end := closingBacktickRun(s, i+n, n); end >= 0 {
+				emit(i, inlineToken{Kind: codeToken, Raw: s[i : end+n]})
+				i = end + n
+				continue
+			}
+			i += n
+			continue
+		case strings.HasPrefix(s[i:], "<!--"):
+			if end := strings.Index(s[i+4:], "-->"); end >= 0 {
+				emit(i, inlineToken{Kind: commentToken, Raw: s[i : i+4+end+3]})
+				i += 4 + end + 3
+				continue
+			}
+		case strings.HasPrefix(s[i:], "{{<"):
+			if end := strings.Index(s[i:], ">}}"); end >= 0 {
+				emit(i, inlineToken{Kind: shortcodeToken, Raw: s[i : i+end+3]})
+				i += end + 3
+				continue
+			}
+		case s[i] == '<':
+			if t, ok := parseTag(s[i:]); ok {
+				emit(i, t)
+				i += len(t.Raw)
+				continue
+			}
+		}
+		i++
+	}
+	if textStart < len(s) {
+		tokens = append(tokens, inlineToken{Kind: textToken, Raw: s[textStart:]})
+	}
+	return tokens
+}
+
+// backtickRun returns the length of the run of backticks at s[i].
+func backtickRun(s string, i int) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == '`' {
+		n++
+	}
+	return n
+}
+
+// closingBacktickRun returns the index of the next run of exactly n backticks
+// at or after from, or -1.
+func closingBacktickRun(s string, from, n int) int {
+	for i := from; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		m := backtickRun(s, i)
+		if m == n {
+			return i
+		}
+		i += m
+	}
+	return -1
+}
+
+// parseTag parses the tag at the start of s: <name attr="v" ...>, </name> or
+// <name ... />. It reports false if s does not start with a well-formed tag.
+func parseTag(s string) (inlineToken, bool) {
+	t := inlineToken{Kind: tagToken}
+	i := 1
+	if i < len(s) && s[i] == '/' {
+		t.Closing = true
+		i++
+	}
+
+	start := i
+	for i < len(s) && (isLetter(s[i]) || i > start && (isDigit(s[i]) || s[i] == '-')) {
+		i++
+	}
+	if i == start {
+		return t, false
+	}
+	t.Name = s[start:i]
+
+	for {
+		spaces := i
+		for i < len(s) && isSpace(s[i]) {
+			i++
+		}
+		if i >= len(s) {
+			return t, false
+		}
+		switch {
+		case s[i] == '>':
+			t.Raw = s[:i+1]
+			return t, true
+		case strings.HasPrefix(s[i:], "/>") && !t.Closing:
+			t.SelfClosing = true
+			t.Raw = s[:i+2]
+			return t, true
+		case i == spaces:
+			// Attributes must be separated from the name and each other.
+			return t, false
+		}
+
+		nameStart := i
+		for i < len(s) && (isLetter(s[i]) || s[i] == '_' || s[i] == ':' || i > nameStart && (isDigit(s[i]) || s[i] == '-' || s[i] == '.')) {
+			i++
+		}
+		if i == nameStart {
+			return t, false
+		}
+		attr := htmlAttr{Name: s[nameStart:i]}
+
+		if i < len(s) && s[i] == '=' {
+			i++
+			switch {
+			case i < len(s) && (s[i] == '"' || s[i] == '\''):
+				end := strings.IndexByte(s[i+1:], s[i])
+				if end < 0 {
+					return t, false
+				}
+				attr.Value = s[i+1 : i+1+end]
+				i += end + 2
+			default:
+				valueStart := i
+				for i < len(s) && !isSpace(s[i]) && !strings.ContainsRune("\"'=<>`", rune(s[i])) {
+					i++
+				}
+				if i == valueStart {
+					return t, false
+				}
+				attr.Value = s[valueStart:i]
+			}
+		}
+		t.Attrs = append(t.Attrs, attr)
+	}
+}
+
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+func isSpace(c byte) bool  { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
+
+// escapeAngleBracketPlaceholders makes a line of Markdown safe for MDX. Tags
+// of mdxElements are kept, HTML comments become MDX comments, and any other
+// "<...>", such as <src-path>, is escaped as {"<"}src-path{">"}. Code spans and
+// Hugo shortcodes are left untouched.
+func escapeAngleBracketPlaceholders(line string) string {
+	var b strings.Builder
+	for _, t := range tokenizeInline(line) {
+		switch t.Kind {
+		case commentToken:
+			b.WriteString("{/*" + strings.TrimSuffix(strings.TrimPrefix(t.Raw, "<!--"), "-->") + "*/}")
+		case tagToken:
+			if t.allowed() {
+				b.WriteString(t.Raw)
+			} else {
+				b.WriteString(escapeText(t.Raw))
+			}
+		case textToken:
+			b.WriteString(escapeText(t.Raw))
+		default:
+			b.WriteString(t.Raw)
+		}
+	}
+	return b.String()
+}
+
+// escapeText escapes every "<" of a piece of text, and the ">" closing it.
+func escapeText(s string) string {
+	if !strings.Contains(s, "<") {
+		return s
+	}
+	var b strings.Builder
+	open := false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '<':
+			b.WriteString(`{"<"}`)
+			open = true
+		case s[i] == '>' && open:
+			b.WriteString(`{">"}`)
+			open = false
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// splitTableCells splits the content of a table row, without its outer pipes,
+// into trimmed cells. Pipes inside code spans, tags, comments, shortcodes and
+// between a matched pair of start and end tags do not separate cells.
+func splitTableCells(content string) []string {
+	tokens := tokenizeInline(content)
+
+	// Mark the tokens enclosed by a start tag and its end tag.
+	enclosed := make([]int, len(tokens)+1)
+	var open []int
+	for i, t := range tokens {
+		if t.Kind != tagToken || t.SelfClosing {
+			continue
+		}
+		if !t.Closing {
+			open = append(open, i)
+			continue
+		}
+		for j := len(open) - 1; j >= 0; j-- {
+			if tokens[open[j]].Name == t.Name {
+				enclosed[open[j]+1]++
+				enclosed[i]--
+				open = open[:j]
+				break
+			}
+		}
+	}
+
+	var cells []string
+	var cell strings.Builder
+	depth := 0
+	for i, t := range tokens {
+		depth += enclosed[i]
+		if t.Kind != textToken || depth > 0 {
+			cell.WriteString(t.Raw)
+			continue
+		}
+		parts := strings.Split(t.Raw, "|")
+		for j, part := range parts {
+			if j > 0 {
+				cells = append(cells, strings.TrimSpace(cell.String()))
+				cell.Reset()
+			}
+			cell.WriteString(part)
+		}
+	}
+	if cell.Len() > 0 || len(cells) > 0 {
+		cells = append(cells, strings.TrimSpace(cell.String()))
+	}
+	return cells
+}
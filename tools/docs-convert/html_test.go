@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEscapeAngleBracketPlaceholders(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"Copy <src-path> to <dest-path>.", `Copy {"<"}src-path{">"} to {"<"}dest-path{">"}.`},
+		{"<preset> is not <pre>pre</pre>", `{"<"}preset{">"} is not <pre>pre</pre>`},
+		{"<thing> in a <th>", `{"<"}thing{">"} in a <th>`},
+		{"<trusted-ca> in a <tr>", `{"<"}trusted-ca{">"} in a <tr>`},
+		{"Set <node IP> here", `Set {"<"}node IP{">"} here`},
+		{"x < y and x <= z", `x {"<"} y and x {"<"}= z`},
+		{`<a href="#x">link</a><br />`, `<a href="#x">link</a><br />`},
+		{`<a href="#x" onclick="f()">link</a>`, `{"<"}a href="#x" onclick="f()"{">"}link</a>`},
+		{"`<node>` and ``a ` <b>``", "`<node>` and ``a ` <b>``"},
+		{"it`s <foo>", "it`s {\"<\"}foo{\">\"}"},
+		{"a <!-- note --> b", "a {/* note */} b"},
+		{"{{< highlight yaml >}}", "{{< highlight yaml >}}"},
+	} {
+		if got := escapeAngleBracketPlaceholders(tc.in); got != tc.want {
+			t.Errorf("escapeAngleBracketPlaceholders(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestSplitTableCells(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want []string
+	}{
+		{"a | b", []string{"a", "b"}},
+		{"a | `x|y` | b", []string{"a", "`x|y`", "b"}},
+		{`<a href="#x" title="1|2">t</a> | c`, []string{`<a href="#x" title="1|2">t</a>`, "c"}},
+		{"<details><summary>s</summary>a|b</details> | c", []string{"<details><summary>s</summary>a|b</details>", "c"}},
+		{"<code>a|b</code> | <x-y>|z</x-y> | d", []string{"<code>a|b</code>", "<x-y>|z</x-y>", "d"}},
+		{"<p> a | b", []string{"<p> a", "b"}},
+	} {
+		if got := splitTableCells(tc.in); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("splitTableCells(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestTableWithPipesInCode(t *testing.T) {
+	source := "---\ntitle: T\n---\n\n| Field | Description |\n|-------|-------------|\n|`a|b` | Matches <value> or `x|y`. |\n"
+	var buf bytes.Buffer
+	convertLines(&buf, strings.Split(source, "\n"), "t.mdx", convertOptions{})
+	out := buf.String()
+	for _, s := range []string{"<td>`a|b`</td>", "<td>Matches {\"<\"}value{\">\"} or `x|y`.</td>"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("missing %q:\n%s", s, out)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// documentMapName is the generated list of every configuration document. The
+// hand-kept document-map.mdx is a different page and is preserved.
+const documentMapName = "documents.mdx"
+
+// groupTitles overrides the title derived from a group folder name.
+var groupTitles = map[string]string{
+	"cri":        "CRI",
+	"siderolink": "SideroLink",
+	"v1alpha1":   "v1alpha1",
+}
+
+// groupTitle returns the display title of a configuration group folder.
+func groupTitle(group string) string {
+	if t, ok := groupTitles[group]; ok {
+		return t
+	}
+	if group == "" {
+		return ""
+	}
+	return strings.ToUpper(group[:1]) + group[1:]
+}
+
+// documentGroup returns the group folder of a schema page, "" for a page at
+// the top of the reference.
+func documentGroup(page string) string {
+	if dir := path.Dir(page); dir != "." {
+		return dir
+	}
+	return ""
+}
+
+// isMultiDocument reports whether a document is named and may therefore
+// appear several times in a machine configuration.
+func isMultiDocument(doc schemaDocument) bool {
+	for _, f := range doc.Fields {
+		if f.Path == "name" {
+			return true
+		}
+	}
+	return false
+}
+
+// planIndexPages renders an index page per configuration group and the
+// top-level document map from the schema of the converted documents.
+func planIndexPages(schema *configSchema, dstDir string, opts convertOptions) []plannedFile {
+	groups := map[string][]schemaDocument{}
+	for _, doc := range schema.Documents {
+		if g := documentGroup(doc.Page); g != "" {
+			groups[g] = append(groups[g], doc)
+		}
+	}
+	var names []string
+	for g := range groups {
+		names = append(names, g)
+	}
+	sort.Strings(names)
+
+	var plan []plannedFile
+	for _, g := range names {
+		docs := groups[g]
+		sort.Slice(docs, func(i, j int) bool { return docs[i].Kind < docs[j].Kind })
+
+		path := filepath.Join(dstDir, g+".mdx")
+		var b bytes.Buffer
+		writeGeneratedFrontmatter(&b, path, groupTitle(g), fmt.Sprintf("%s configuration documents.", groupTitle(g)), opts.frontmatter())
+		fmt.Fprintf(&b, "\nThe %s group contains the following configuration documents.\n\n", groupTitle(g))
+		fmt.Fprintln(&b, "| Document | apiVersion | Multi-document | Description |")
+		fmt.Fprintln(&b, "| --- | --- | --- | --- |")
+		for _, doc := range docs {
+			fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", documentLink(doc), apiVersionCell(doc), multiDocumentCell(doc), tableCell(oneLine(doc.Description)))
+		}
+		plan = append(plan, plannedFile{Name: g + ".mdx", Path: path, Content: b.Bytes()})
+	}
+
+	docs := append([]schemaDocument{}, schema.Documents...)
+	sort.SliceStable(docs, func(i, j int) bool {
+		gi, gj := documentGroup(docs[i].Page), documentGroup(docs[j].Page)
+		if gi != gj {
+			return gi < gj
+		}
+		return docs[i].Kind < docs[j].Kind
+	})
+
+	path := filepath.Join(dstDir, documentMapName)
+	var b bytes.Buffer
+	writeGeneratedFrontmatter(&b, path, "Configuration documents", "Every configuration document with its apiVersion and kind.", opts.frontmatter())
+	fmt.Fprintln(&b, "\nMulti-document kinds are named: they may appear several times in a machine configuration, once per `name`.")
+	fmt.Fprintln(&b, "\n| Document | Group | apiVersion | Multi-document | Description |")
+	fmt.Fprintln(&b, "| --- | --- | --- | --- | --- |")
+	for _, doc := range docs {
+		group := "-"
+		if g := documentGroup(doc.Page); g != "" {
+			group = fmt.Sprintf("[%s](./%s)", groupTitle(g), g)
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s |\n", documentLink(doc), group, apiVersionCell(doc), multiDocumentCell(doc), tableCell(oneLine(doc.Description)))
+	}
+	plan = append(plan, plannedFile{Name: documentMapName, Path: path, Content: b.Bytes()})
+
+	return plan
+}
+
+func documentLink(doc schemaDocument) string {
+	return fmt.Sprintf("[`%s`](./%s)", doc.Kind, doc.Page)
+}
+
+func apiVersionCell(doc schemaDocument) string {
+	if doc.APIVersion == "" {
+		return "-"
+	}
+	return "`" + doc.APIVersion + "`"
+}
+
+func multiDocumentCell(doc schemaDocument) string {
+	if isMultiDocument(doc) {
+		return "Yes"
+	}
+	return "No"
+}
+
+// oneLine collapses a multi-line description into one line.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlanIndexPages(t *testing.T) {
+	schema := &configSchema{Documents: []schemaDocument{
+		{Kind: "HostnameConfig", APIVersion: "v1alpha1", Page: "network/hostnameconfig", Description: "HostnameConfig configures\nthe hostname.",
+			Fields: []schemaField{{Path: "hostname"}}},
+		{Kind: "LinkConfig", APIVersion: "v1alpha1", Page: "network/linkconfig", Description: "LinkConfig | configures a link.",
+			Fields: []schemaField{{Path: "name"}, {Path: "mtu"}}},
+		{Kind: "RegistryAuthConfig", APIVersion: "v1alpha1", Page: "cri/registryauthconfig", Fields: []schemaField{{Path: "name"}}},
+	}}
+
+	dstDir := filepath.Join("out", "configuration")
+	pages := map[string]string{}
+	for _, p := range planIndexPages(schema, dstDir, convertOptions{}) {
+		if p.Path != filepath.Join(dstDir, p.Name) {
+			t.Errorf("%s is planned at %s", p.Name, p.Path)
+		}
+		pages[p.Name] = string(p.Content)
+	}
+	if len(pages) != 3 {
+		t.Fatalf("got pages %v, want cri.mdx, network.mdx and %s", pages, documentMapName)
+	}
+
+	network := pages["network.mdx"]
+	for _, s := range []string{
+		"title: Network\n",
+		"| [`HostnameConfig`](./network/hostnameconfig) | `v1alpha1` | No | HostnameConfig configures the hostname. |",
+		"| [`LinkConfig`](./network/linkconfig) | `v1alpha1` | Yes | LinkConfig \\| configures a link. |",
+	} {
+		if !strings.Contains(network, s) {
+			t.Errorf("network.mdx missing %q:\n%s", s, network)
+		}
+	}
+	if !strings.Contains(pages["cri.mdx"], "title: CRI\n") {
+		t.Errorf("cri.mdx should use the CRI title:\n%s", pages["cri.mdx"])
+	}
+
+	documents := pages[documentMapName]
+	if i, j := strings.Index(documents, "RegistryAuthConfig"), strings.Index(documents, "HostnameConfig"); i < 0 || j < i {
+		t.Errorf("documents should be ordered by group, then kind:\n%s", documents)
+	}
+	if !strings.Contains(documents, "| [`RegistryAuthConfig`](./cri/registryauthconfig) | [CRI](./cri) | `v1alpha1` | Yes |") {
+		t.Errorf("unexpected document map:\n%s", documents)
+	}
+}
+
+func TestSyncKeepsHandWrittenDocumentMap(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), filepath.Join(t.TempDir(), "configuration")
+	writeSource(t, srcDir, "network/hostnameconfig.md", hostnameSource)
+	writeSource(t, dstDir, "document-map.mdx", "hand-written\n")
+
+	out := syncDir(t, srcDir, dstDir, syncOptions{})
+	if strings.Contains(out, "document-map.mdx") {
+		t.Errorf("document-map.mdx should be preserved:\n%s", out)
+	}
+}
@@ -1,47 +1,69 @@
 package main
 
 import (
-	"bufio"
+	"bytes"
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 )
 
+// convertOptions selects optional output modes.
+type convertOptions struct {
+	// ParamFields renders config-field tables as Mintlify <ParamField>
+	// entries instead of HTML tables.
+	ParamFields bool
+	// SplitCLI splits the talosctl CLI reference into one page per command
+	// group plus an index page.
+	SplitCLI bool
+	// IndexPages adds an index page per configuration group and a map of
+	// every configuration document.
+	IndexPages bool
+	// Frontmatter sets frontmatter fields and imports per page; nil selects
+	// the embedded default rules.
+	Frontmatter *frontmatterRules
+}
+
+// convertFile converts srcPath with the default options and writes the result
+// to dstPath.
 func convertFile(srcPath, dstPath string) error {
-	src, err := os.Open(srcPath)
+	content, err := renderFile(srcPath, dstPath, convertOptions{})
 	if err != nil {
 		return err
 	}
-	defer src.Close()
+	return os.WriteFile(dstPath, content, 0644)
+}
 
-	dst, err := os.Create(dstPath)
+// renderFile converts srcPath and returns the MDX that would be written to
+// dstPath, without touching the destination.
+func renderFile(srcPath, dstPath string, opts convertOptions) ([]byte, error) {
+	lines, err := readLines(srcPath)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer dst.Close()
 
-	scanner := bufio.NewScanner(src)
-	writer := bufio.NewWriter(dst)
-	defer writer.Flush()
-
-	var lines []string
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
+	var buf bytes.Buffer
+	convertLines(&buf, lines, dstPath, opts)
+	return buf.Bytes(), nil
+}
 
-	if err := scanner.Err(); err != nil {
-		return err
-	}
+// convertLines writes the MDX conversion of the source lines to writer. dstPath
+// selects the frontmatter rules that apply to the page.
+func convertLines(writer *bytes.Buffer, lines []string, dstPath string, opts convertOptions) {
 
 	// Trim excessive trailing blank lines (keep at most 1)
 	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
 		lines = lines[:len(lines)-1]
 	}
 
-	// Check if this is the v1alpha1/config file or cli file
-	isConfigFile := strings.Contains(dstPath, "v1alpha1/config.mdx") || strings.Contains(dstPath, "v1alpha1\\config.mdx")
-	isCliFile := strings.HasSuffix(dstPath, "/cli.mdx") || strings.HasSuffix(dstPath, "\\cli.mdx")
+	page := opts.frontmatter().forPage(dstPath)
+
+	// The first frontmatter block is collected here so the rules can be
+	// applied to it as a whole.
+	var frontmatter *bytes.Buffer
+	out := writer
 
 	// Process lines
 	i := 0
@@ -60,33 +82,36 @@ func convertFile(srcPath, dstPath string) error {
 			if !inFrontmatter {
 				inFrontmatter = true
 				fmt.Fprintln(writer, line)
+				if frontmatter == nil {
+					frontmatter = &bytes.Buffer{}
+					writer = frontmatter
+				}
 				i++
 				continue
 			} else {
 				// End of frontmatter
 				inFrontmatter = false
+				applyRules := writer == frontmatter
+				if applyRules {
+					writer = out
+					writer.WriteString(page.applyFrontmatter(frontmatter.String()))
+				}
 				fmt.Fprintln(writer, line)
 				// Add auto-generated comment after frontmatter
-				fmt.Fprintln(writer, "")
-				fmt.Fprintln(writer, "{/*")
-				fmt.Fprintln(writer, "This file is automatically generated from source documentation.")
-				fmt.Fprintln(writer, "Do not edit manually. For more information, see https://github.com/siderolabs/docs")
-				fmt.Fprintln(writer, "*/}")
+				writeGeneratedComment(writer)
+				if applyRules {
+					page.writeImports(writer)
+				}
 				i++
-				continue
-			}
-		}
 
-		// Handle title in frontmatter for config and cli files
-		if inFrontmatter && strings.HasPrefix(line, "title:") {
-			if isConfigFile {
-				fmt.Fprintln(writer, "title: MachineConfig")
-				i++
-				continue
-			}
-			if isCliFile {
-				fmt.Fprintln(writer, "title: talosctl")
-				i++
+				// Configuration pages are rendered from their parsed
+				// structure rather than line by line.
+				if opts.ParamFields {
+					if doc := parseReference(lines); doc != nil {
+						writeParamFieldBody(writer, doc)
+						return
+					}
+				}
 				continue
 			}
 		}
@@ -198,7 +223,10 @@ func convertFile(srcPath, dstPath string) error {
 		i++
 	}
 
-	return nil
+	// An unterminated frontmatter block is written as it was.
+	if writer != out {
+		out.Write(frontmatter.Bytes())
+	}
 }
 
 func fixAnchorLinks(line string) string {
@@ -253,118 +281,6 @@ func fixAnchorLinks(line string) string {
 	return result
 }
 
-func escapeAngleBracketPlaceholders(line string) string {
-	result := ""
-	i := 0
-	inBackticks := false
-
-	for i < len(line) {
-		// Track backtick state
-		if line[i] == '`' {
-			inBackticks = !inBackticks
-			result += string(line[i])
-			i++
-			continue
-		}
-
-		// Check for HTML comment pattern <!--
-		if i+3 < len(line) && line[i:i+4] == "<!--" {
-			// Find the closing -->
-			end := strings.Index(line[i:], "-->")
-			if end != -1 {
-				// Extract comment content and convert to MDX format
-				commentContent := line[i+4 : i+end] // Extract text between <!-- and -->
-				result += "{/*" + commentContent + "*/}"
-				i = i + end + 3 // +3 for "-->"
-				continue
-			}
-		}
-
-		// Check for Hugo shortcode start pattern {{<
-		if i+2 < len(line) && line[i:i+3] == "{{<" {
-			// Find the closing >}}
-			end := strings.Index(line[i:], ">}}")
-			if end != -1 {
-				// Skip the entire Hugo shortcode
-				end = i + end + 3 // +3 for ">}}"
-				result += line[i:end]
-				i = end
-				continue
-			}
-		}
-
-		if line[i] == '<' {
-			// Find the closing >
-			end := i + 1
-			for end < len(line) && line[end] != '>' {
-				end++
-			}
-			if end < len(line) {
-				// Extract the content between < and >
-				content := line[i+1 : end]
-				// Check if it's likely a placeholder or text that should not be parsed as HTML
-				// Known HTML tags we want to preserve: a, br, p, Accordion, details, summary, pre, code, td, th, tr, table, thead, tbody and their closing tags
-				isKnownTag := strings.HasPrefix(content, "a ") ||
-					content == "a" ||
-					strings.HasPrefix(content, "br") ||
-					content == "p" ||
-					strings.HasPrefix(content, "p ") ||
-					strings.HasPrefix(content, "Accordion") ||
-					strings.HasPrefix(content, "details") ||
-					strings.HasPrefix(content, "summary") ||
-					strings.HasPrefix(content, "pre") ||
-					content == "pre" ||
-					strings.HasPrefix(content, "code") ||
-					content == "code" ||
-					strings.HasPrefix(content, "td") ||
-					content == "td" ||
-					strings.HasPrefix(content, "th") ||
-					content == "th" ||
-					strings.HasPrefix(content, "tr") ||
-					content == "tr" ||
-					strings.HasPrefix(content, "table") ||
-					strings.HasPrefix(content, "thead") ||
-					strings.HasPrefix(content, "tbody") ||
-					content == "/a" ||
-					content == "/br" ||
-					content == "/p" ||
-					content == "/Accordion" ||
-					content == "/details" ||
-					content == "/summary" ||
-					content == "/pre" ||
-					content == "/code" ||
-					content == "/td" ||
-					content == "/th" ||
-					content == "/tr" ||
-					content == "/table" ||
-					content == "/thead" ||
-					content == "/tbody"
-
-				// If it's not a known HTML tag, escape it
-				if !isKnownTag {
-					// Inside backticks, leave as-is (no escaping needed in code context)
-					// Outside backticks, use JSX expressions
-					if inBackticks {
-						result += line[i : end+1]
-					} else {
-						result += `{"<"}` + content + `{">"}`
-					}
-					i = end + 1
-					continue
-				} else {
-					// For known HTML tags, copy the entire tag at once
-					result += line[i : end+1]
-					i = end + 1
-					continue
-				}
-			}
-		}
-		result += string(line[i])
-		i++
-	}
-	return result
-}
-
 // detectTableStart checks if a line is the start of a markdown table
 func detectTableStart(line string) bool {
 	trimmed := strings.TrimSpace(line)
@@ -458,41 +374,8 @@ func parseTableRow(lines []string, startIdx int) ([]string, int) {
 	trimmed := strings.TrimSpace(fullRow)
 	content := strings.Trim(trimmed, "|")
 
-	// Split by | but be smarter about it - handle | inside <details> tags
-	cells := make([]string, 0)
-	currentCell := ""
-	inDetails := false
-	i := 0
-
-	for i < len(content) {
-		// Check for <details> opening
-		if i+8 <= len(content) && content[i:i+8] == "<details" {
-			inDetails = true
-		}
-		// Check for </details> closing
-		if i+10 <= len(content) && content[i:i+10] == "</details>" {
-			currentCell += "</details>"
-			i += 10
-			inDetails = false
-			continue
-		}
-
-		// If we hit a | and we're not inside <details>, it's a cell separator
-		if content[i] == '|' && !inDetails {
-			cells = append(cells, strings.TrimSpace(currentCell))
-			currentCell = ""
-			i++
-			continue
-		}
-
-		currentCell += string(content[i])
-		i++
-	}
-
-	// Add the last cell
-	if len(currentCell) > 0 || len(cells) > 0 {
-		cells = append(cells, strings.TrimSpace(currentCell))
-	}
+	// Split by |, except inside code spans and inline HTML
+	cells := splitTableCells(content)
 
 	return cells, currentIdx
 }
@@ -731,13 +614,62 @@ func convertTableToHTML(lines []string, startIdx int) (string, int) {
 }
 
 func main() {
-	if len(os.Args) < 3 {
-		fmt.Println("Usage: convert-docs <source_file_or_dir> <dest_file_or_dir>")
+	if len(os.Args) > 1 && os.Args[1] == "config-diff" {
+		os.Exit(runConfigDiff(os.Args[2:]))
+	}
+
+	dryRun := flag.Bool("dry-run", false, "report what would change without writing or removing any files")
+	showDiff := flag.Bool("diff", false, "print a unified diff for every added, removed or modified file")
+	paramFields := flag.Bool("param-fields", false, "render config-field tables as Mintlify ParamField/Expandable components instead of HTML tables")
+	schemaPath := flag.String("schema", "", "also write a JSON index of every config document and field to this file (directory mode only)")
+	splitCLI := flag.Bool("split-cli", false, "split cli.mdx into an index page and one page per talosctl command group under cli/ (directory mode only)")
+	strictExamples := flag.Bool("strict-examples", false, "fail without writing anything if a YAML example in the generated pages is invalid")
+	indexPages := flag.Bool("index-pages", false, "also generate an index page per configuration group and a documents.mdx map of every configuration document (directory mode only)")
+	cliNav := flag.String("cli-nav", "", "with --split-cli, also write the navigation group for the CLI pages to this YAML file")
+	matrixPath := flag.String("matrix", "", "convert every version of a YAML file mapping versions to raw docs directories; the destination and --schema/--cli-nav paths must contain {version}")
+	rulesPath := flag.String("frontmatter-rules", "", "YAML file of rules setting frontmatter fields, version imports and the version banner per page (default: built-in rules)")
+	stableVersion := flag.String("stable-version", "", "latest stable version, substituted for {stable} in the frontmatter rules")
+	jobs := flag.Int("jobs", runtime.GOMAXPROCS(0), "with --matrix, number of versions converted in parallel")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: convert-docs [--dry-run] [--diff] [--param-fields] [--schema file.json] [--split-cli [--cli-nav nav.yaml]] [--index-pages] [--frontmatter-rules rules.yaml [--stable-version v1.x]] [--strict-examples] <source_file_or_dir> <dest_file_or_dir>")
+		fmt.Fprintln(os.Stderr, "       convert-docs --matrix matrix.yaml [flags] <dest_dir_with_{version}>")
+		fmt.Fprintln(os.Stderr, "       convert-docs config-diff [flags] <old> <new>")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	rules, err := loadFrontmatterRules(*rulesPath, *stableVersion)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading frontmatter rules: %v\n", err)
 		os.Exit(1)
 	}
 
-	src := os.Args[1]
-	dst := os.Args[2]
+	run := runOptions{
+		Convert:        convertOptions{ParamFields: *paramFields, SplitCLI: *splitCLI, IndexPages: *indexPages, Frontmatter: rules},
+		Sync:           syncOptions{DryRun: *dryRun, Diff: *showDiff},
+		StrictExamples: *strictExamples,
+	}
+	if *cliNav != "" && !*splitCLI {
+		fmt.Fprintln(os.Stderr, "Error: --cli-nav requires --split-cli")
+		os.Exit(1)
+	}
+
+	if *matrixPath != "" {
+		if flag.NArg() != 1 {
+			flag.Usage()
+			os.Exit(1)
+		}
+		template := directoryJob{DstDir: flag.Arg(0), SchemaPath: *schemaPath, CLINav: *cliNav}
+		os.Exit(runMatrix(os.Stdout, *matrixPath, template, *jobs, run))
+	}
+
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	src := flag.Arg(0)
+	dst := flag.Arg(1)
 
 	// Check if source is a file or directory
 	srcInfo, err := os.Stat(src)
@@ -778,117 +710,40 @@ func main() {
 
 		fmt.Printf("Converting single file: %s -> %s\n", src, dstPath)
 
-		// Create destination directory if it doesn't exist
-		if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
-			fmt.Fprintf(os.Stderr, "Error creating destination directory: %v\n", err)
-			os.Exit(1)
-		}
-
-		// Convert the file
-		if err := convertFile(src, dstPath); err != nil {
+		content, err := renderFile(src, dstPath, run.Convert)
+		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error converting file: %v\n", err)
 			os.Exit(1)
 		}
 
-		fmt.Println("Conversion complete!")
-		return
-	}
-
-	// Directory conversion (existing behavior)
-	srcDir := src
-	dstDir := dst
-
-	fmt.Printf("Converting docs from %s to %s\n", srcDir, dstDir)
-
-	// Files to preserve during conversion (don't delete these)
-	preserveFiles := map[string]bool{
-		"overview.mdx": true,
-	}
-
-	// Instead of removing entire directory, selectively remove only generated files
-	// This preserves manually created files like overview.mdx
-	if _, err := os.Stat(dstDir); err == nil {
-		// Directory exists, remove only .mdx files that aren't in preserve list
-		filepath.Walk(dstDir, func(path string, info os.FileInfo, err error) error {
-			// Skip entries we can't stat and directories, and keep walking:
-			// returning err here would abort the whole cleanup pass.
-			if err != nil || info.IsDir() {
-				return nil //nolint:nilerr // intentional: skip this entry, continue the walk
-			}
-
-			filename := filepath.Base(path)
-			if !preserveFiles[filename] && strings.HasSuffix(path, ".mdx") {
-				os.Remove(path)
-			}
-			return nil
-		})
-	} else {
-		// Directory doesn't exist, create it
-		os.MkdirAll(dstDir, 0755)
-	}
-
-	// Walk through source directory
-	err = filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			return nil
-		}
-
-		if !strings.HasSuffix(path, ".md") {
-			return nil
+		plan := []plannedFile{{Name: filepath.Base(dstPath), Path: dstPath, Content: content}}
+		if !checkExamples(os.Stderr, plan) && run.StrictExamples {
+			os.Exit(1)
 		}
-
-		relPath, err := filepath.Rel(srcDir, path)
+		changes, err := diffPlan(plan, nil)
 		if err != nil {
-			return err
-		}
-
-		// Skip _index.md files
-		if strings.Contains(relPath, "_index.md") {
-			fmt.Printf("Skipping %s\n", relPath)
-			return nil
+			fmt.Fprintf(os.Stderr, "Error comparing with existing output: %v\n", err)
+			os.Exit(1)
 		}
-
-		// Special handling for cli.md at root - move it to parent directory
-		var dstPath string
-		if relPath == "cli.md" {
-			// Output to parent directory of dstDir
-			// Clean the path first to remove trailing slashes
-			cleanDstDir := filepath.Clean(dstDir)
-			parentDir := filepath.Dir(cleanDstDir)
-			dstPath = filepath.Join(parentDir, "cli.mdx")
-			fmt.Printf("Converting %s -> cli.mdx (moved to parent directory: %s)\n", relPath, dstPath)
-		} else {
-			// Convert .md to .mdx in normal location
-			dstPath = filepath.Join(dstDir, strings.TrimSuffix(relPath, ".md")+".mdx")
-			fmt.Printf("Converting %s -> %s\n", relPath, strings.TrimSuffix(relPath, ".md")+".mdx")
+		if err := applyChanges(os.Stdout, changes, run.Sync); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing output: %v\n", err)
+			os.Exit(1)
 		}
 
-		// Create destination directory
-		if err := os.MkdirAll(filepath.Dir(dstPath), 0755); err != nil {
-			return err
-		}
+		fmt.Println("Conversion complete!")
+		return
+	}
 
-		return convertFile(path, dstPath)
-	})
+	// Directory conversion
+	job := directoryJob{SrcDir: src, DstDir: dst, SchemaPath: *schemaPath, CLINav: *cliNav}
+	fmt.Printf("Converting docs from %s to %s\n", job.SrcDir, job.DstDir)
 
+	converted, err := convertDirectory(os.Stdout, os.Stderr, job, run)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Count converted files
-	count := 0
-	filepath.Walk(dstDir, func(path string, info os.FileInfo, err error) error {
-		if err == nil && !info.IsDir() && strings.HasSuffix(path, ".mdx") {
-			count++
-		}
-		return nil
-	})
-
 	fmt.Println("Conversion complete!")
-	fmt.Printf("Converted files: %d\n", count)
+	fmt.Printf("Converted files: %d\n", converted)
 }
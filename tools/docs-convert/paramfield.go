@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// writeParamFieldBody renders a parsed configuration page as Mintlify
+// <ParamField> entries instead of HTML tables. A field whose type is
+// documented by another section of the same page gets that section nested
+// in an <Expandable>, so the section is not repeated on its own.
+func writeParamFieldBody(writer *bytes.Buffer, doc *refDoc) {
+	byAnchor := doc.sectionByAnchor()
+
+	nested := map[string]bool{}
+	for _, s := range doc.Sections {
+		for _, f := range s.Fields {
+			if f.TypeAnchor != "" && byAnchor[f.TypeAnchor] != nil {
+				nested[f.TypeAnchor] = true
+			}
+		}
+	}
+
+	// ids holds the anchors already written, so a section nested under
+	// several fields gets its anchor only once.
+	ids := map[string]bool{}
+	for _, s := range doc.Sections {
+		if s.Anchor != "" && nested[s.Anchor] {
+			continue
+		}
+		if s.Name != "" {
+			fmt.Fprintf(writer, "\n%s %s\n", strings.Repeat("#", s.Level), s.Name)
+		}
+		writeSectionBody(writer, s, byAnchor, map[string]bool{s.Anchor: true}, ids)
+	}
+}
+
+// writeSectionBody writes a section's description, examples and fields.
+// visiting holds the anchors being expanded, to stop on recursive types.
+func writeSectionBody(writer *bytes.Buffer, s *refSection, byAnchor map[string]*refSection, visiting, ids map[string]bool) {
+	if len(s.Description) > 0 {
+		fmt.Fprintln(writer)
+		for _, line := range s.Description {
+			fmt.Fprintln(writer, convertInline(line))
+		}
+	}
+	for _, ex := range s.Examples {
+		fmt.Fprintf(writer, "\n```yaml\n%s\n```\n", ex)
+	}
+	for _, f := range s.Fields {
+		writeParamField(writer, f, byAnchor, visiting, ids)
+	}
+}
+
+// writeParamField writes one field, nesting the section of its type if the
+// type is documented on the same page. The nested section has no heading, so
+// an <a id> keeps the anchor that links to the section point at it.
+func writeParamField(writer *bytes.Buffer, f *refField, byAnchor map[string]*refSection, visiting, ids map[string]bool) {
+	fmt.Fprintf(writer, "\n<ParamField path=%q type=%q>\n", f.Name, f.Type)
+
+	if len(f.Description) > 0 {
+		fmt.Fprintln(writer)
+		for _, line := range f.Description {
+			fmt.Fprintln(writer, convertInline(line))
+		}
+	}
+
+	if len(f.Values) > 0 {
+		values := make([]string, len(f.Values))
+		for i, v := range f.Values {
+			values[i] = "`" + v + "`"
+		}
+		fmt.Fprintf(writer, "\nAllowed values: %s\n", strings.Join(values, ", "))
+	}
+
+	if len(f.Examples) > 0 {
+		fmt.Fprintln(writer, "\n**Examples:**")
+		for _, ex := range f.Examples {
+			fmt.Fprintf(writer, "\n```yaml\n%s\n```\n", ex)
+		}
+	}
+
+	if child := byAnchor[f.TypeAnchor]; child != nil && !visiting[child.Anchor] {
+		visiting[child.Anchor] = true
+		fmt.Fprintf(writer, "\n<Expandable title=%q>\n", strings.TrimPrefix(f.Type, "[]"))
+		if id := strings.ToLower(child.Name); id != "" && !ids[id] {
+			ids[id] = true
+			fmt.Fprintf(writer, "\n<a id=%q></a>\n", id)
+		}
+		writeSectionBody(writer, child, byAnchor, visiting, ids)
+		fmt.Fprintln(writer, "\n</Expandable>")
+		delete(visiting, child.Anchor)
+	}
+
+	fmt.Fprintln(writer, "\n</ParamField>")
+}
+
+// convertInline applies the inline conversions used for table cells to one
+// line of prose, without collapsing it into a single cell.
+func convertInline(line string) string {
+	line = cleanLinkText(line)
+	line = fixAnchorLinks(line)
+	line = escapeAngleBracketPlaceholders(line)
+	return wrapTechnicalPatternsInBackticks(line)
+}
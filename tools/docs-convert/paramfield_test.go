@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const configSource = `---
+description: Config defines the v1alpha1.Config Talos machine configuration document.
+title: Config
+---
+
+<!-- markdownlint-disable -->
+
+{{< highlight yaml >}}
+version: v1alpha1
+machine: # ...
+{{< /highlight >}}
+
+
+| Field | Type | Description | Value(s) |
+|-------|------|-------------|----------|
+|` + "`version`" + ` |string |Indicates the schema used to decode the contents.  |` + "`v1alpha1`" + `<br /> |
+|` + "`machine`" + ` |<a href="#Config.machine">MachineConfig</a> |Provides machine specific configuration options.  | |
+
+
+
+## machine {#Config.machine}
+
+MachineConfig represents the machine-specific config values.
+
+
+
+
+| Field | Type | Description | Value(s) |
+|-------|------|-------------|----------|
+|` + "`type`" + ` |string |Defines the role of the machine.<br><br>Second paragraph.  |` + "`controlplane`" + `<br />` + "`worker`" + `<br /> |
+|` + "`token`" + ` |string |The token used to join the cluster. <details><summary>Show example(s)</summary>{{< highlight yaml >}}
+token: 328hom.uqjzh6jnn2eie9oi
+{{< /highlight >}}</details> | |
+|` + "`env`" + ` |Env |Environment variables, e.g. <key>=<value>.  | |
+`
+
+func TestParseReference(t *testing.T) {
+	doc := parseReference(strings.Split(configSource, "\n"))
+	if doc == nil {
+		t.Fatal("parseReference returned nil for a config page")
+	}
+	if len(doc.Sections) != 2 {
+		t.Fatalf("got %d sections, want 2", len(doc.Sections))
+	}
+
+	machine := doc.Sections[1]
+	if machine.Name != "machine" || machine.Anchor != "Config.machine" || machine.Level != 2 {
+		t.Errorf("unexpected machine section: %+v", machine)
+	}
+
+	root := doc.Sections[0]
+	if got := root.Fields[1]; got.Type != "MachineConfig" || got.TypeAnchor != "Config.machine" {
+		t.Errorf("linked type not parsed: %+v", got)
+	}
+
+	typ := machine.Fields[0]
+	if want := []string{"Defines the role of the machine.", "", "Second paragraph."}; !reflect.DeepEqual(typ.Description, want) {
+		t.Errorf("description = %q, want %q", typ.Description, want)
+	}
+	if want := []string{"controlplane", "worker"}; !reflect.DeepEqual(typ.Values, want) {
+		t.Errorf("values = %q, want %q", typ.Values, want)
+	}
+
+	token := machine.Fields[1]
+	if want := []string{"token: 328hom.uqjzh6jnn2eie9oi"}; !reflect.DeepEqual(token.Examples, want) {
+		t.Errorf("examples = %q, want %q", token.Examples, want)
+	}
+	if want := []string{"The token used to join the cluster."}; !reflect.DeepEqual(token.Description, want) {
+		t.Errorf("example not removed from description: %q", token.Description)
+	}
+}
+
+func TestParseReferenceIgnoresPagesWithoutFieldTables(t *testing.T) {
+	src := "---\ntitle: CLI\n---\n\n## talosctl\n\n| A | B |\n|---|---|\n| 1 | 2 |\n"
+	if doc := parseReference(strings.Split(src, "\n")); doc != nil {
+		t.Errorf("expected nil for a page without field tables, got %+v", doc)
+	}
+}
+
+func TestParamFieldOutput(t *testing.T) {
+	var buf bytes.Buffer
+	convertLines(&buf, strings.Split(configSource, "\n"), "v1alpha1/config.mdx", convertOptions{ParamFields: true})
+	out := buf.String()
+
+	for _, want := range []string{
+		"title: MachineConfig",
+		`<ParamField path="version" type="string">`,
+		"Allowed values: `v1alpha1`",
+		`<ParamField path="machine" type="MachineConfig">`,
+		"<Expandable title=\"MachineConfig\">\n\n<a id=\"machine\"></a>\n",
+		"Defines the role of the machine.\n\nSecond paragraph.",
+		"```yaml\ntoken: 328hom.uqjzh6jnn2eie9oi\n```",
+		`Environment variables, e.g. {"<"}key{">"}={"<"}value{">"}.`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	// The machine section is nested under its field, not repeated, and
+	// keeps its anchor.
+	if strings.Contains(out, "## machine") {
+		t.Errorf("nested section was also rendered on its own:\n%s", out)
+	}
+	if strings.Contains(out, "<table>") {
+		t.Errorf("table rendered in ParamField mode:\n%s", out)
+	}
+	if strings.Count(out, "<ParamField") != strings.Count(out, "</ParamField>") {
+		t.Errorf("unbalanced ParamField tags:\n%s", out)
+	}
+}
+
+func TestParamFieldRecursiveTypeTerminates(t *testing.T) {
+	src := "---\ntitle: T\n---\n\n| Field | Type | Description | Value(s) |\n|---|---|---|---|\n" +
+		"|`node` |<a href=\"#T.node\">Node</a> |A node.  | |\n\n\n\n" +
+		"## node {#T.node}\n\nNode.\n\n| Field | Type | Description | Value(s) |\n|---|---|---|---|\n" +
+		"|`child` |<a href=\"#T.node\">Node</a> |A child node.  | |\n"
+
+	var buf bytes.Buffer
+	convertLines(&buf, strings.Split(src, "\n"), "t.mdx", convertOptions{ParamFields: true})
+	if n := strings.Count(buf.String(), "<Expandable"); n != 1 {
+		t.Errorf("recursive type expanded %d times, want 1:\n%s", n, buf.String())
+	}
+}
+
+func TestChangedFieldsInParamFieldOutput(t *testing.T) {
+	oldPage := "<ParamField path=\"machine\" type=\"M\">\n\n<ParamField path=\"type\" type=\"string\">\n\nold\n\n</ParamField>\n\n</ParamField>\n"
+	newPage := strings.Replace(oldPage, "old", "new", 1)
+	if got, want := changedFields(oldPage, newPage), []string{"machine.type"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("changedFields = %v, want %v", got, want)
+	}
+}
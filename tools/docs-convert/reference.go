@@ -0,0 +1,214 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// refDoc is the structure of one `talosctl docs` configuration page: a list of
+// sections, each documenting one struct with its description, YAML examples
+// and field table. The first section is the document itself and has no
+// heading.
+type refDoc struct {
+	Sections []*refSection
+}
+
+// refSection documents one struct of a configuration document.
+type refSection struct {
+	Name        string // heading text, e.g. "install" or "destinations[]"
+	Anchor      string // upstream anchor without the trailing dot, e.g. "Config.machine.install"
+	Level       int    // heading level; 1 for the document section
+	Description []string
+	Examples    []string
+	Fields      []*refField
+}
+
+// refField is one row of a Field | Type | Description | Value(s) table.
+type refField struct {
+	Name        string
+	Type        string   // type name as displayed, e.g. "[]LoggingDestination"
+	TypeAnchor  string   // anchor of the section documenting the type, if linked
+	Description []string // one entry per <br>-separated line; "" marks a paragraph break
+	Values      []string // allowed values
+	Examples    []string
+}
+
+var (
+	// A heading with an optional Hugo anchor: "## machine {#Config.machine}".
+	refHeadingRe = regexp.MustCompile(`^(#{2,6})\s+(.*?)\s*(?:\{#([^}]*)\})?\s*$`)
+	// A type cell linking to the section that documents the type.
+	typeLinkRe = regexp.MustCompile(`^<a href="#([^"]*)">([^<]*)</a>$`)
+	// A <details> block inside a description cell.
+	detailsRe = regexp.MustCompile(`(?s)<details><summary>(.*?)</summary>(.*?)</details>`)
+	// A Hugo YAML highlight block.
+	highlightRe = regexp.MustCompile(`(?s)\{\{< highlight yaml >\}\}\n?(.*?)\{\{< /highlight >\}\}`)
+	brRe        = regexp.MustCompile(`<br\s*/?>`)
+)
+
+// fieldTableHeader is the header of the tables `talosctl docs` emits for
+// struct fields.
+var fieldTableHeader = []string{"Field", "Type", "Description", "Value(s)"}
+
+// parseReference parses the body of a configuration page. It returns nil when
+// the page has no field tables, e.g. the CLI reference.
+func parseReference(lines []string) *refDoc {
+	doc := &refDoc{}
+	current := &refSection{Level: 1}
+	doc.Sections = append(doc.Sections, current)
+	hasFields := false
+
+	i := 0
+	// Skip the frontmatter.
+	if len(lines) > 0 && lines[0] == "---" {
+		for i = 1; i < len(lines) && lines[i] != "---"; i++ {
+		}
+		i++
+	}
+
+	for i < len(lines) {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case strings.Contains(line, "<!-- markdownlint-disable -->"):
+			i++
+
+		case refHeadingRe.MatchString(line):
+			m := refHeadingRe.FindStringSubmatch(line)
+			current = &refSection{
+				Name:   m[2],
+				Anchor: strings.TrimSuffix(m[3], "."),
+				Level:  len(m[1]),
+			}
+			doc.Sections = append(doc.Sections, current)
+			i++
+
+		case trimmed == "{{< highlight yaml >}}":
+			end := i + 1
+			for end < len(lines) && strings.TrimSpace(lines[end]) != "{{< /highlight >}}" {
+				end++
+			}
+			current.Examples = append(current.Examples, strings.Join(lines[i+1:min(end, len(lines))], "\n"))
+			i = end + 1
+
+		case isFieldTable(lines, i):
+			// Skip the header and separator rows.
+			_, next := parseTableRow(lines, i)
+			next++
+			for next < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[next]), "|") {
+				var cells []string
+				cells, next = parseTableRow(lines, next)
+				if f := parseFieldRow(cells); f != nil {
+					current.Fields = append(current.Fields, f)
+					hasFields = true
+				}
+			}
+			i = next
+
+		default:
+			if trimmed != "" || len(current.Description) > 0 {
+				current.Description = append(current.Description, trimmed)
+			}
+			i++
+		}
+	}
+
+	if !hasFields {
+		return nil
+	}
+	for _, s := range doc.Sections {
+		for len(s.Description) > 0 && s.Description[len(s.Description)-1] == "" {
+			s.Description = s.Description[:len(s.Description)-1]
+		}
+	}
+	return doc
+}
+
+// isFieldTable reports whether a field table starts at lines[i].
+func isFieldTable(lines []string, i int) bool {
+	if !detectTableStart(lines[i]) || i+1 >= len(lines) || !isTableSeparator(lines[i+1]) {
+		return false
+	}
+	header, _ := parseTableRow(lines, i)
+	if len(header) != len(fieldTableHeader) {
+		return false
+	}
+	for j, h := range header {
+		if h != fieldTableHeader[j] {
+			return false
+		}
+	}
+	return true
+}
+
+// parseFieldRow turns the cells of a field table row into a refField.
+func parseFieldRow(cells []string) *refField {
+	if len(cells) < 3 {
+		return nil
+	}
+	name := strings.Trim(cells[0], "`")
+	if name == "" {
+		return nil
+	}
+
+	f := &refField{Name: name, Type: cells[1]}
+	if m := typeLinkRe.FindStringSubmatch(cells[1]); m != nil {
+		f.TypeAnchor = strings.TrimSuffix(m[1], ".")
+		f.Type = m[2]
+	}
+
+	// Example blocks live in <details><summary>Show example(s)</summary>;
+	// older releases also folded long descriptions into a <details> block
+	// whose summary is the first line.
+	desc := detailsRe.ReplaceAllStringFunc(cells[2], func(block string) string {
+		m := detailsRe.FindStringSubmatch(block)
+		if strings.HasPrefix(m[1], "Show example") {
+			for _, ex := range highlightRe.FindAllStringSubmatch(m[2], -1) {
+				f.Examples = append(f.Examples, strings.TrimRight(ex[1], "\n"))
+			}
+			return ""
+		}
+		return m[1] + "<br>" + m[2]
+	})
+	f.Description = splitBreaks(desc)
+
+	if len(cells) > 3 {
+		for _, v := range brRe.Split(cells[3], -1) {
+			if v = strings.Trim(strings.TrimSpace(v), "`"); v != "" {
+				f.Values = append(f.Values, v)
+			}
+		}
+	}
+	return f
+}
+
+// splitBreaks splits a table cell on its <br> line breaks. A double break
+// becomes a single "" entry marking a paragraph break.
+func splitBreaks(cell string) []string {
+	var out []string
+	for _, part := range brRe.Split(strings.TrimSpace(cell), -1) {
+		part = strings.Join(strings.Fields(part), " ")
+		if part == "" {
+			if len(out) > 0 && out[len(out)-1] != "" {
+				out = append(out, "")
+			}
+			continue
+		}
+		out = append(out, part)
+	}
+	for len(out) > 0 && out[len(out)-1] == "" {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+// sectionByAnchor indexes the document's sections by anchor.
+func (d *refDoc) sectionByAnchor() map[string]*refSection {
+	byAnchor := map[string]*refSection{}
+	for _, s := range d.Sections {
+		if s.Anchor != "" {
+			byAnchor[s.Anchor] = s
+		}
+	}
+	return byAnchor
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configSchema is the machine-readable index of every configuration document
+// of one Talos version, written next to the MDX with --schema.
+type configSchema struct {
+	Version   string           `json:"version,omitempty"`
+	Documents []schemaDocument `json:"documents"`
+}
+
+// schemaDocument describes one configuration document kind.
+type schemaDocument struct {
+	Kind        string        `json:"kind"`
+	APIVersion  string        `json:"apiVersion,omitempty"`
+	Title       string        `json:"title"`
+	Description string        `json:"description,omitempty"`
+	Page        string        `json:"page"` // page path relative to the output directory, without extension
+	Examples    []string      `json:"examples,omitempty"`
+	Fields      []schemaField `json:"fields"`
+}
+
+// schemaField describes one field of a configuration document.
+type schemaField struct {
+	Path        string   `json:"path"` // dotted path from the document root; list elements are marked with []
+	Type        string   `json:"type"`
+	Description string   `json:"description,omitempty"`
+	Values      []string `json:"values,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	Examples    []string `json:"examples,omitempty"`
+	Deprecated  bool     `json:"deprecated,omitempty"`
+	Deprecation string   `json:"deprecation,omitempty"` // the sentence announcing the deprecation
+	Anchor      string   `json:"anchor"`                // page path and heading anchor of the section documenting the field
+}
+
+var (
+	// "Defaults to `true`.", "default is 10.0.0.0/8", "Default value: 1500":
+	// a backticked value, or else one token without trailing punctuation.
+	defaultRe = regexp.MustCompile("(?i)\\bdefault(?:s to| is| value:|:)\\s+(?:`([^`]+)`|([^\\s,;]*[^\\s.,;]))")
+	// An explicit deprecation: a sentence starting with "Deprecated" or "This
+	// field is deprecated", not a mention such as "still supported but deprecated".
+	deprecatedRe = regexp.MustCompile(`(?i)(?:^|[.!?]\s+)(?:deprecated\b|this (?:field|feature|option|setting|document) (?:is|has been) deprecated\b)`)
+	// Version folders such as public/talos/v1.14/...
+	versionDirRe = regexp.MustCompile(`(?:^|[/\\])(v\d+\.\d+)(?:[/\\]|$)`)
+	// "apiVersion: v1alpha1" / "kind: HostnameConfig" / "version: v1alpha1" at the top level of an example.
+	exampleKeyRe = regexp.MustCompile(`^(apiVersion|kind|version):\s*(\S+)`)
+)
+
+// readLines reads a file into lines without their line endings.
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
+
+// pageFrontmatter is the subset of upstream frontmatter the schema uses.
+type pageFrontmatter struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+}
+
+// parseFrontmatter decodes a leading YAML frontmatter block, if any.
+func parseFrontmatter(lines []string) pageFrontmatter {
+	var fm pageFrontmatter
+	if len(lines) == 0 || lines[0] != "---" {
+		return fm
+	}
+	for i := 1; i < len(lines); i++ {
+		if lines[i] == "---" {
+			_ = yaml.Unmarshal([]byte(strings.Join(lines[1:i], "\n")), &fm)
+			break
+		}
+	}
+	fm.Description = strings.TrimSpace(fm.Description)
+	return fm
+}
+
+// versionFromPath extracts the Talos version folder (e.g. "v1.14") from an
+// output path, or "" if it has none.
+func versionFromPath(path string) string {
+	if m := versionDirRe.FindStringSubmatch(filepath.ToSlash(path)); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// buildSchema parses every configuration page under srcDir into a schema.
+// Pages without field tables (the CLI reference) are skipped. Anchors are the
+// same with and without --param-fields.
+func buildSchema(srcDir, version string) (*configSchema, error) {
+	schema := &configSchema{Version: version, Documents: []schemaDocument{}}
+
+	err := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !strings.HasSuffix(path, ".md") || strings.Contains(path, "_index.md") {
+			return nil
+		}
+		rel, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+
+		lines, err := readLines(path)
+		if err != nil {
+			return err
+		}
+		doc := parseReference(lines)
+		if doc == nil {
+			return nil
+		}
+		schema.Documents = append(schema.Documents, schemaForPage(filepath.ToSlash(strings.TrimSuffix(rel, ".md")), parseFrontmatter(lines), doc))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(schema.Documents, func(i, j int) bool { return schema.Documents[i].Page < schema.Documents[j].Page })
+	return schema, nil
+}
+
+// schemaForPage flattens a parsed page into a schema document.
+func schemaForPage(page string, fm pageFrontmatter, doc *refDoc) schemaDocument {
+	root := doc.Sections[0]
+	sd := schemaDocument{
+		Kind:        fm.Title,
+		Title:       fm.Title,
+		Description: fm.Description,
+		Page:        page,
+		Examples:    root.Examples,
+		Fields:      []schemaField{},
+	}
+
+	// The document kind and API version are only stated in the examples.
+	for _, ex := range root.Examples {
+		for _, line := range strings.Split(ex, "\n") {
+			m := exampleKeyRe.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			switch m[1] {
+			case "kind":
+				sd.Kind = m[2]
+			case "apiVersion", "version":
+				if sd.APIVersion == "" {
+					sd.APIVersion = m[2]
+				}
+			}
+		}
+	}
+
+	byAnchor := doc.sectionByAnchor()
+	reached := map[*refSection]bool{root: true}
+
+	sectionAnchor := func(s *refSection) string {
+		if s.Name == "" {
+			return page
+		}
+		return page + "#" + headingAnchor(s.Name)
+	}
+
+	var walk func(s *refSection, prefix, anchor string)
+	walk = func(s *refSection, prefix, anchor string) {
+		for _, f := range s.Fields {
+			path := prefix + f.Name
+			sd.Fields = append(sd.Fields, schemaFieldFor(path, anchor, f))
+
+			child := byAnchor[f.TypeAnchor]
+			if child == nil || reached[child] {
+				continue
+			}
+			reached[child] = true
+			childPrefix := path + "."
+			if strings.HasPrefix(f.Type, "[]") {
+				childPrefix = path + "[]."
+			}
+			// With --param-fields the child section is nested in its
+			// parent, where an <a id> keeps its anchor.
+			walk(child, childPrefix, sectionAnchor(child))
+		}
+	}
+	walk(root, "", page)
+
+	// Sections not linked from any field are still part of the document;
+	// derive their path from the upstream anchor instead.
+	for _, s := range doc.Sections {
+		if reached[s] || len(s.Fields) == 0 {
+			continue
+		}
+		prefix := ""
+		if _, rest, ok := strings.Cut(s.Anchor, "."); ok {
+			prefix = rest + "."
+		}
+		reached[s] = true
+		walk(s, prefix, sectionAnchor(s))
+	}
+
+	return sd
+}
+
+// schemaFieldFor builds the schema entry for one field.
+func schemaFieldFor(path, anchor string, f *refField) schemaField {
+	desc := strings.Join(f.Description, "\n")
+	sf := schemaField{
+		Path:        path,
+		Type:        f.Type,
+		Description: desc,
+		Values:      f.Values,
+		Examples:    f.Examples,
+		Anchor:      anchor,
+	}
+	if m := defaultRe.FindStringSubmatch(desc); m != nil {
+		sf.Default = strings.Trim(m[1]+m[2], "'\"")
+	}
+	for _, line := range f.Description {
+		if deprecatedRe.MatchString(strings.TrimSpace(line)) {
+			sf.Deprecated = true
+			sf.Deprecation = line
+			break
+		}
+	}
+	return sf
+}
+
+// headingAnchor returns the anchor Mintlify gives a generated section heading,
+// matching the links fixAnchorLinks writes.
+func headingAnchor(heading string) string {
+	anchor := strings.ToLower(heading)
+	anchor = strings.ReplaceAll(anchor, "[", "%5B")
+	return strings.ReplaceAll(anchor, "]", "%5D")
+}
+
+// marshalSchema renders the schema as indented JSON with a trailing newline.
+// HTML escaping is off so placeholders such as <key> stay readable.
+func marshalSchema(schema *configSchema) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(schema); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func findSchemaField(doc schemaDocument, path string) *schemaField {
+	for i := range doc.Fields {
+		if doc.Fields[i].Path == path {
+			return &doc.Fields[i]
+		}
+	}
+	return nil
+}
+
+func TestBuildSchema(t *testing.T) {
+	srcDir := t.TempDir()
+	writeSource(t, srcDir, "network/hostnameconfig.md", hostnameSource)
+	writeSource(t, srcDir, "v1alpha1/config.md", configSource)
+	writeSource(t, srcDir, "cli.md", "---\ntitle: CLI\n---\n\n## talosctl\n\nNo fields here.\n")
+
+	schema, err := buildSchema(srcDir, versionFromPath(filepath.Join("public", "talos", "v1.14", "reference")))
+	if err != nil {
+		t.Fatalf("buildSchema: %v", err)
+	}
+	if schema.Version != "v1.14" {
+		t.Errorf("version = %q, want v1.14", schema.Version)
+	}
+	if len(schema.Documents) != 2 {
+		t.Fatalf("got %d documents, want 2 (the CLI page has no fields)", len(schema.Documents))
+	}
+
+	hostname := schema.Documents[0]
+	if hostname.Kind != "HostnameConfig" || hostname.Page != "network/hostnameconfig" {
+		t.Errorf("unexpected document: kind=%q page=%q", hostname.Kind, hostname.Page)
+	}
+
+	config := schema.Documents[1]
+	if config.APIVersion != "v1alpha1" {
+		t.Errorf("apiVersion = %q, want v1alpha1", config.APIVersion)
+	}
+	typ := findSchemaField(config, "machine.type")
+	if typ == nil {
+		t.Fatalf("nested field machine.type missing: %+v", config.Fields)
+	}
+	if typ.Anchor != "v1alpha1/config#machine" {
+		t.Errorf("anchor = %q, want v1alpha1/config#machine", typ.Anchor)
+	}
+	if len(typ.Values) != 2 {
+		t.Errorf("values = %v, want two allowed values", typ.Values)
+	}
+	if token := findSchemaField(config, "machine.token"); token == nil || len(token.Examples) != 1 {
+		t.Errorf("example missing from machine.token: %+v", token)
+	}
+}
+
+func TestSchemaFieldDefaultAndDeprecation(t *testing.T) {
+	f := schemaFieldFor("install.wipe", "p", &refField{
+		Name:        "wipe",
+		Type:        "bool",
+		Description: []string{"Wipe the disk.", "Defaults to `true`.", "", "Deprecated: use VolumeConfig instead."},
+	})
+	if f.Default != "true" {
+		t.Errorf("default = %q, want true", f.Default)
+	}
+	if !f.Deprecated || f.Deprecation != "Deprecated: use VolumeConfig instead." {
+		t.Errorf("deprecation not detected: %+v", f)
+	}
+}
+
+func TestSchemaFieldDefaultValues(t *testing.T) {
+	for desc, want := range map[string]string{
+		"Defaults to `1m` when the node is idle.": "1m",
+		"The default is 10.0.0.0/8.":              "10.0.0.0/8",
+		"Default value: 1500, in bytes.":          "1500",
+		"Defaults to \"stable\";":                 "stable",
+		"No default.":                             "",
+	} {
+		f := schemaFieldFor("a", "p", &refField{Name: "a", Description: []string{desc}})
+		if f.Default != want {
+			t.Errorf("%q: default = %q, want %q", desc, f.Default, want)
+		}
+	}
+}
+
+func TestSchemaFieldDeprecationMarker(t *testing.T) {
+	for desc, want := range map[string]bool{
+		"Deprecated: use VolumeConfig instead.":                       true,
+		"Sets the mode. This field is deprecated.":                    true,
+		`That value is still supported but deprecated.`:               false,
+		"Merged with the deprecated v1alpha1 machine.sysctls values.": false,
+	} {
+		f := schemaFieldFor("a", "p", &refField{Name: "a", Description: []string{desc}})
+		if f.Deprecated != want {
+			t.Errorf("%q: deprecated = %v, want %v", desc, f.Deprecated, want)
+		}
+	}
+}
+
+func TestSchemaAnchorsMatchParamFieldOutput(t *testing.T) {
+	srcDir := t.TempDir()
+	writeSource(t, srcDir, "v1alpha1/config.md", configSource)
+
+	schema, err := buildSchema(srcDir, "")
+	if err != nil {
+		t.Fatalf("buildSchema: %v", err)
+	}
+	var buf bytes.Buffer
+	convertLines(&buf, strings.Split(configSource, "\n"), "v1alpha1/config.mdx", convertOptions{ParamFields: true})
+	f := findSchemaField(schema.Documents[0], "machine.type")
+	if f == nil {
+		t.Fatal("machine.type missing")
+	}
+	_, anchor, _ := strings.Cut(f.Anchor, "#")
+	if !strings.Contains(buf.String(), `<a id="`+anchor+`"></a>`) {
+		t.Errorf("anchor %q of the nested section missing from ParamField output:\n%s", f.Anchor, buf.String())
+	}
+}
+
+func TestMarshalSchemaKeepsPlaceholders(t *testing.T) {
+	data, err := marshalSchema(&configSchema{Documents: []schemaDocument{{Kind: "K", Fields: []schemaField{{Path: "env", Description: "<key>=<value>"}}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back configSchema
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	if !json.Valid(data) || string(data[len(data)-1]) != "\n" {
+		t.Error("schema should be valid JSON ending in a newline")
+	}
+	if want := `"description": "<key>=<value>"`; !strings.Contains(string(data), want) {
+		t.Errorf("placeholders were escaped:\n%s", data)
+	}
+}
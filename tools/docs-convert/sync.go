@@ -0,0 +1,345 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Files in the destination directory that are written by hand and must never
+// be removed, even though docs-convert does not generate them.
+var preserveFiles = map[string]bool{
+	"overview.mdx":     true,
+	"document-map.mdx": true,
+}
+
+// syncOptions controls how planned output is written to disk.
+type syncOptions struct {
+	DryRun bool // report only, never write or remove files
+	Diff   bool // print a unified diff for every change
+}
+
+// plannedFile is one generated page, rendered in memory.
+type plannedFile struct {
+	Name    string // path relative to the destination directory, for reporting
+	Path    string // path the page is written to
+	Content []byte
+}
+
+type changeKind int
+
+const (
+	unchanged changeKind = iota
+	added
+	modified
+	removed
+)
+
+func (k changeKind) String() string {
+	switch k {
+	case added:
+		return "added"
+	case modified:
+		return "modified"
+	case removed:
+		return "removed"
+	default:
+		return "unchanged"
+	}
+}
+
+// fileChange is the result of comparing one planned page with what is on disk.
+type fileChange struct {
+	Name string
+	Path string
+	Kind changeKind
+	Old  []byte
+	New  []byte
+}
+
+// planDirectory converts every .md file under srcDir in memory and returns the
+// pages that belong in dstDir, sorted by name.
+func planDirectory(w io.Writer, srcDir, dstDir string, opts convertOptions) ([]plannedFile, error) {
+	var plan []plannedFile
+	err := filepath.Walk(srcDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !strings.HasSuffix(path, ".md") {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(srcDir, path)
+		if err != nil {
+			return err
+		}
+
+		// Skip _index.md files
+		if strings.Contains(relPath, "_index.md") {
+			fmt.Fprintf(w, "Skipping %s\n", relPath)
+			return nil
+		}
+
+		// Special handling for cli.md at root - move it to parent directory
+		name := strings.TrimSuffix(relPath, ".md") + ".mdx"
+		dstPath := filepath.Join(dstDir, name)
+		if relPath == "cli.md" {
+			// Clean the path first to remove trailing slashes
+			parentDir := filepath.Dir(filepath.Clean(dstDir))
+			dstPath = filepath.Join(parentDir, "cli.mdx")
+			name = filepath.Join("..", "cli.mdx")
+
+			if opts.SplitCLI {
+				pages, err := planCLIPages(path, dstPath, opts)
+				if err != nil {
+					return fmt.Errorf("converting %s: %w", relPath, err)
+				}
+				plan = append(plan, pages...)
+				return nil
+			}
+		}
+
+		content, err := renderFile(path, dstPath, opts)
+		if err != nil {
+			return fmt.Errorf("converting %s: %w", relPath, err)
+		}
+		plan = append(plan, plannedFile{Name: filepath.ToSlash(name), Path: dstPath, Content: content})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(plan, func(i, j int) bool { return plan[i].Name < plan[j].Name })
+	return plan, nil
+}
+
+// existingOutputs lists the generated .mdx pages already present in dstDir,
+// keyed by path, with their names relative to dstDir. The cli.mdx that
+// planDirectory writes next to dstDir is included. Preserved hand-written
+// files are left out so they are never reported as removed.
+func existingOutputs(dstDir string) (map[string]string, error) {
+	existing := map[string]string{}
+	cliPath := filepath.Join(filepath.Dir(filepath.Clean(dstDir)), "cli.mdx")
+	if _, err := os.Stat(cliPath); err == nil {
+		existing[cliPath] = "../cli.mdx"
+	}
+	err := filepath.WalkDir(dstDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".mdx") || preserveFiles[d.Name()] {
+			return nil
+		}
+		rel, err := filepath.Rel(dstDir, path)
+		if err != nil {
+			return err
+		}
+		existing[path] = filepath.ToSlash(rel)
+		return nil
+	})
+	return existing, err
+}
+
+// diffPlan compares the planned pages with the files on disk. Existing pages
+// that are not part of the plan are reported as removed.
+func diffPlan(plan []plannedFile, existing map[string]string) ([]fileChange, error) {
+	planned := map[string]bool{}
+	changes := make([]fileChange, 0, len(plan))
+
+	for _, p := range plan {
+		planned[p.Path] = true
+
+		old, err := os.ReadFile(p.Path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			changes = append(changes, fileChange{Name: p.Name, Path: p.Path, Kind: added, New: p.Content})
+		case err != nil:
+			return nil, err
+		case bytes.Equal(old, p.Content):
+			changes = append(changes, fileChange{Name: p.Name, Path: p.Path, Kind: unchanged, Old: old, New: p.Content})
+		default:
+			changes = append(changes, fileChange{Name: p.Name, Path: p.Path, Kind: modified, Old: old, New: p.Content})
+		}
+	}
+
+	for path, name := range existing {
+		if planned[path] {
+			continue
+		}
+		old, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, fileChange{Name: name, Path: path, Kind: removed, Old: old})
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Name < changes[j].Name })
+	return changes, nil
+}
+
+// applyChanges reports every change to w and, unless this is a dry run, writes
+// added and modified pages and removes stale ones. Unchanged pages are never
+// rewritten, so their modification time and git status stay untouched.
+func applyChanges(w io.Writer, changes []fileChange, opts syncOptions) error {
+	counts := map[changeKind]int{}
+
+	for _, c := range changes {
+		counts[c.Kind]++
+		if c.Kind == unchanged {
+			continue
+		}
+
+		fmt.Fprintf(w, "  %-8s %s\n", c.Kind, c.Name)
+		if c.Kind == modified {
+			if fields := changedFields(string(c.Old), string(c.New)); len(fields) > 0 {
+				fmt.Fprintf(w, "           fields: %s\n", strings.Join(fields, ", "))
+			}
+		}
+		if opts.Diff {
+			fmt.Fprint(w, unifiedDiff("a/"+c.Name, "b/"+c.Name, c.Old, c.New))
+		}
+
+		if opts.DryRun {
+			continue
+		}
+		switch c.Kind {
+		case removed:
+			if err := os.Remove(c.Path); err != nil {
+				return err
+			}
+		default:
+			if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+				return err
+			}
+			if err := os.WriteFile(c.Path, c.New, 0644); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "Summary: %d added, %d removed, %d modified, %d unchanged\n",
+		counts[added], counts[removed], counts[modified], counts[unchanged])
+	if opts.DryRun {
+		fmt.Fprintln(w, "Dry run: no files were written.")
+	}
+	return nil
+}
+
+var (
+	headingLineRe = regexp.MustCompile(`^#{1,6}\s+(.*\S)\s*$`)
+	fieldCellRe   = regexp.MustCompile("^\\s*<td[^>]*>`([^`]+)`</td>")
+	paramFieldRe  = regexp.MustCompile(`^<ParamField path="([^"]*)"`)
+)
+
+// pageFields maps every config field rendered on a page to the text of its
+// table row or <ParamField>. Fields are keyed by the heading of the section
+// they appear in, so `machine.type` and `cluster.type` are told apart; nested
+// ParamFields are keyed by their full path. The frontmatter is included under
+// the "frontmatter" key.
+func pageFields(content string) map[string]string {
+	fields := map[string]string{}
+	lines := strings.Split(content, "\n")
+
+	i := 0
+	if len(lines) > 0 && lines[0] == "---" {
+		for i = 1; i < len(lines) && lines[i] != "---"; i++ {
+		}
+		fields["frontmatter"] = strings.Join(lines[:min(i+1, len(lines))], "\n")
+	}
+
+	section := ""
+	var row []string
+	rowField := ""
+
+	type openParam struct {
+		path  string
+		lines []string
+	}
+	var params []*openParam
+
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if m := headingLineRe.FindStringSubmatch(line); m != nil {
+			section = m[1]
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case paramFieldRe.MatchString(trimmed):
+			path := paramFieldRe.FindStringSubmatch(trimmed)[1]
+			if len(params) > 0 {
+				path = params[len(params)-1].path + "." + path
+			} else if section != "" {
+				path = section + "." + path
+			}
+			params = append(params, &openParam{path: path, lines: []string{trimmed}})
+		case trimmed == "</ParamField>" && len(params) > 0:
+			p := params[len(params)-1]
+			params = params[:len(params)-1]
+			fields[p.path] = strings.Join(p.lines, "\n")
+		case len(params) > 0:
+			p := params[len(params)-1]
+			p.lines = append(p.lines, trimmed)
+		case trimmed == "<tr>":
+			row = []string{}
+			rowField = ""
+		case trimmed == "</tr>" && row != nil:
+			if rowField != "" {
+				key := rowField
+				if section != "" {
+					key = section + "." + rowField
+				}
+				fields[key] = strings.Join(row, "\n")
+			}
+			row = nil
+		case row != nil:
+			if len(row) == 0 {
+				if m := fieldCellRe.FindStringSubmatch(line); m != nil {
+					rowField = m[1]
+				}
+			}
+			row = append(row, trimmed)
+		}
+	}
+	return fields
+}
+
+// changedFields returns the sorted names of the fields that differ between two
+// renderings of a page. Added fields are prefixed with "+", removed ones with
+// "-"; fields whose row changed are listed as-is.
+func changedFields(oldContent, newContent string) []string {
+	oldFields := pageFields(oldContent)
+	newFields := pageFields(newContent)
+
+	var names []string
+	for name, row := range newFields {
+		oldRow, ok := oldFields[name]
+		switch {
+		case !ok:
+			names = append(names, "+"+name)
+		case oldRow != row:
+			names = append(names, name)
+		}
+	}
+	for name := range oldFields {
+		if _, ok := newFields[name]; !ok {
+			names = append(names, "-"+name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return strings.TrimLeft(names[i], "+-") < strings.TrimLeft(names[j], "+-")
+	})
+	return names
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const hostnameSource = `---
+description: HostnameConfig configures the hostname.
+title: HostnameConfig
+---
+
+| Field | Type | Description | Value(s) |
+|-------|------|-------------|----------|
+|` + "`auto`" + ` |AutoHostnameKind |A method to generate a hostname.  |` + "`stable`" + `<br /> |
+|` + "`hostname`" + ` |string |A static hostname.  | |
+`
+
+func writeSource(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func syncDir(t *testing.T, srcDir, dstDir string, opts syncOptions) string {
+	t.Helper()
+	plan, err := planDirectory(io.Discard, srcDir, dstDir, convertOptions{})
+	if err != nil {
+		t.Fatalf("planDirectory: %v", err)
+	}
+	existing, err := existingOutputs(dstDir)
+	if err != nil {
+		t.Fatalf("existingOutputs: %v", err)
+	}
+	changes, err := diffPlan(plan, existing)
+	if err != nil {
+		t.Fatalf("diffPlan: %v", err)
+	}
+	var out bytes.Buffer
+	if err := applyChanges(&out, changes, opts); err != nil {
+		t.Fatalf("applyChanges: %v", err)
+	}
+	return out.String()
+}
+
+func TestSyncOnlyRewritesChangedFiles(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), filepath.Join(t.TempDir(), "configuration")
+	writeSource(t, srcDir, "network/hostnameconfig.md", hostnameSource)
+	writeSource(t, srcDir, "network/other.md", "---\ntitle: Other\n---\n\nBody.\n")
+
+	out := syncDir(t, srcDir, dstDir, syncOptions{})
+	if !strings.Contains(out, "Summary: 2 added, 0 removed, 0 modified, 0 unchanged") {
+		t.Fatalf("unexpected first-run summary:\n%s", out)
+	}
+
+	// Mark the page so a rewrite would be detectable.
+	otherPath := filepath.Join(dstDir, "network", "other.mdx")
+	old := time.Unix(1000, 0)
+	if err := os.Chtimes(otherPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	writeSource(t, srcDir, "network/hostnameconfig.md", strings.Replace(hostnameSource, "A static hostname.", "A static host name.", 1))
+	out = syncDir(t, srcDir, dstDir, syncOptions{})
+	if !strings.Contains(out, "Summary: 0 added, 0 removed, 1 modified, 1 unchanged") {
+		t.Fatalf("unexpected second-run summary:\n%s", out)
+	}
+	if !strings.Contains(out, "fields: hostname") {
+		t.Errorf("changed field not reported:\n%s", out)
+	}
+
+	info, err := os.Stat(otherPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.ModTime().Equal(old) {
+		t.Error("unchanged page was rewritten")
+	}
+}
+
+func TestSyncRemovesStalePagesButKeepsPreserved(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	writeSource(t, srcDir, "network/hostnameconfig.md", hostnameSource)
+	writeSource(t, dstDir, "network/gone.mdx", "stale\n")
+	writeSource(t, dstDir, "overview.mdx", "hand written\n")
+
+	out := syncDir(t, srcDir, dstDir, syncOptions{})
+	if !strings.Contains(out, "removed  network/gone.mdx") {
+		t.Errorf("stale page not reported as removed:\n%s", out)
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "network", "gone.mdx")); !os.IsNotExist(err) {
+		t.Error("stale page was not removed")
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "overview.mdx")); err != nil {
+		t.Error("preserved overview.mdx was removed")
+	}
+}
+
+func TestSyncRemovesStaleCLIPage(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), filepath.Join(t.TempDir(), "configuration")
+	writeSource(t, srcDir, "network/hostnameconfig.md", hostnameSource)
+	cliPath := filepath.Join(filepath.Dir(dstDir), "cli.mdx")
+	writeSource(t, filepath.Dir(dstDir), "cli.mdx", "stale\n")
+
+	out := syncDir(t, srcDir, dstDir, syncOptions{DryRun: true})
+	if !strings.Contains(out, "removed  ../cli.mdx") {
+		t.Errorf("stale cli.mdx not reported by the dry run:\n%s", out)
+	}
+	if _, err := os.Stat(cliPath); err != nil {
+		t.Error("dry run removed cli.mdx")
+	}
+
+	syncDir(t, srcDir, dstDir, syncOptions{})
+	if _, err := os.Stat(cliPath); !os.IsNotExist(err) {
+		t.Error("stale cli.mdx was not removed")
+	}
+}
+
+func TestSyncDryRunWithDiff(t *testing.T) {
+	srcDir, dstDir := t.TempDir(), t.TempDir()
+	writeSource(t, srcDir, "network/hostnameconfig.md", hostnameSource)
+	syncDir(t, srcDir, dstDir, syncOptions{})
+
+	dstPath := filepath.Join(dstDir, "network", "hostnameconfig.mdx")
+	before, err := os.ReadFile(dstPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeSource(t, srcDir, "network/hostnameconfig.md", strings.Replace(hostnameSource, "A static hostname.", "A static host name.", 1))
+	writeSource(t, srcDir, "network/new.md", "---\ntitle: New\n---\n\nBody.\n")
+	out := syncDir(t, srcDir, dstDir, syncOptions{DryRun: true, Diff: true})
+
+	for _, want := range []string{
+		"--- a/network/hostnameconfig.mdx",
+		"-      <td>A static hostname.</td>",
+		"+      <td>A static host name.</td>",
+		"+++ b/network/new.mdx",
+		"Dry run: no files were written.",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	after, err := os.ReadFile(dstPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("dry run modified an existing page")
+	}
+	if _, err := os.Stat(filepath.Join(dstDir, "network", "new.mdx")); !os.IsNotExist(err) {
+		t.Error("dry run created a new page")
+	}
+}
+
+func TestChangedFieldsMarksAddedAndRemoved(t *testing.T) {
+	row := func(name, desc string) string {
+		return "    <tr>\n      <td>`" + name + "`</td>\n      <td>" + desc + "</td>\n    </tr>\n"
+	}
+	oldPage := "---\ntitle: T\n---\n\n## machine\n\n" + row("type", "a") + row("token", "b")
+	newPage := "---\ntitle: T\n---\n\n## machine\n\n" + row("type", "changed") + row("ca", "c")
+
+	got := changedFields(oldPage, newPage)
+	want := []string{"+machine.ca", "-machine.token", "machine.type"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changedFields = %v, want %v", got, want)
+	}
+}
+
+func TestUnifiedDiffHunks(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	b := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n"
+	want := "--- a\n+++ b\n@@ -2,7 +2,7 @@\n 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n"
+	if got := unifiedDiff("a", "b", []byte(a), []byte(b)); got != want {
+		t.Errorf("unifiedDiff:\n got: %q\nwant: %q", got, want)
+	}
+	if got := unifiedDiff("a", "b", []byte(a), []byte(a)); got != "" {
+		t.Errorf("equal inputs should produce no diff, got %q", got)
+	}
+}
@@ -0,0 +1,466 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// diagnostic is one construct the MDX compiler would reject.
+type diagnostic struct {
+	Line, Col int
+	Message   string
+}
+
+func (d diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s", d.Line, d.Col, d.Message)
+}
+
+var (
+	openFenceRe = regexp.MustCompile("^[ \t]*(```+|~~~+)")
+	esmStartRe  = regexp.MustCompile(`^(import|export)[\s{*]`)
+	esmImportRe = regexp.MustCompile(`(?s)^import\s*(?:['"]|.*\sfrom\s*['"])`)
+	identRe     = regexp.MustCompile(`^\s*([A-Za-z_$][\w$]*)\s*$`)
+	declaredRe  = regexp.MustCompile(`\b(?:const|let|var|function|class)\s+([A-Za-z_$][\w$]*)`)
+	specifierRe = regexp.MustCompile(`(?:\bas\s+)?([A-Za-z_$][\w$]*)\s*(?:,|}|$)`)
+	autolinkRe  = regexp.MustCompile(`^<[A-Za-z][A-Za-z0-9+.-]*:[^\s<>]*>`)
+)
+
+// analyzer predicts MDX compile failures the way an MDX parser reads a file:
+// frontmatter, fenced code and ESM import/export blocks are set aside, and
+// the rest is scanned for JSX tags, expressions and code spans.
+type analyzer struct {
+	src        string // the file, with frontmatter, fences and ESM blanked out
+	lineStarts []int
+	declared   map[string]bool // names imported or exported by the ESM blocks
+	diags      []diagnostic
+}
+
+// openTag is a JSX element waiting for its closing tag.
+type openTag struct {
+	name string
+	pos  int
+}
+
+// analyzeMDX reports the constructs of content that would fail to compile as
+// MDX: unclosed or mismatched tags, invalid attribute syntax, unbalanced
+// braces, stray "<" and "{", HTML comments, autolinks, malformed ESM, and
+// expressions naming a variable the page never imports.
+func analyzeMDX(content string) []diagnostic {
+	a := &analyzer{declared: map[string]bool{}}
+	a.src = a.blankBlocks(content)
+	a.lineStarts = []int{0}
+	for i := 0; i < len(a.src); i++ {
+		if a.src[i] == '\n' {
+			a.lineStarts = append(a.lineStarts, i+1)
+		}
+	}
+	a.scan()
+	return a.diags
+}
+
+// blankBlocks returns content with the frontmatter, fenced code blocks and
+// ESM blocks replaced by empty lines, so offsets past them keep their line
+// numbers. ESM blocks are checked on the way.
+func (a *analyzer) blankBlocks(content string) string {
+	lines := strings.Split(content, "\n")
+	keep := make([]bool, len(lines))
+
+	i := 0
+	if len(lines) > 0 && isFrontmatterDelim(lines[0]) {
+		for i = 1; i < len(lines) && !isFrontmatterDelim(lines[i]); i++ {
+		}
+		i++
+	}
+
+	prevBlank := true
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if m := openFenceRe.FindStringSubmatch(line); m != nil && !(m[1][0] == '`' && strings.Contains(line[len(m[0]):], "`")) {
+			fence := m[1]
+			start := i
+			for i++; i < len(lines); i++ {
+				if t := strings.TrimSpace(lines[i]); strings.HasPrefix(t, fence) && strings.Trim(t, fence[:1]) == "" {
+					break
+				}
+			}
+			if i == len(lines) {
+				a.report(start+1, 1, "unclosed code fence "+fence)
+			}
+			prevBlank = false
+			continue
+		}
+
+		if prevBlank && esmStartRe.MatchString(line) {
+			start := i
+			for i < len(lines) && strings.TrimSpace(lines[i]) != "" {
+				i++
+			}
+			a.checkESM(start, lines[start:i])
+			prevBlank = true
+			continue
+		}
+
+		keep[i] = true
+		prevBlank = strings.TrimSpace(line) == ""
+	}
+
+	for i, k := range keep {
+		if !k {
+			lines[i] = ""
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// checkESM checks a block of import/export statements starting at line index
+// start and records the names it declares.
+func (a *analyzer) checkESM(start int, lines []string) {
+	block := strings.Join(lines, "\n")
+	if depth := braceDepth(block); depth != 0 {
+		a.report(start+1, 1, "unbalanced braces in import/export block")
+	}
+
+	for _, stmt := range splitStatements(block) {
+		if strings.HasPrefix(stmt, "import") && !esmImportRe.MatchString(stmt) {
+			a.report(start+1+strings.Count(block[:strings.Index(block, stmt)], "\n"), 1, "invalid import statement: "+firstLine(stmt))
+		}
+		declareESM(stmt, a.declared)
+	}
+}
+
+// declareESM records the names an import or export statement declares.
+func declareESM(stmt string, declared map[string]bool) {
+	if strings.HasPrefix(stmt, "import") {
+		names := stmt
+		if i := strings.LastIndex(names, " from"); i >= 0 {
+			names = names[len("import"):i]
+		}
+		for _, m := range specifierRe.FindAllStringSubmatch(names, -1) {
+			declared[m[1]] = true
+		}
+	}
+	for _, m := range declaredRe.FindAllStringSubmatch(stmt, -1) {
+		declared[m[1]] = true
+	}
+}
+
+// splitStatements splits an ESM block at the lines starting a new import or
+// export.
+func splitStatements(block string) []string {
+	var stmts []string
+	var cur []string
+	for _, line := range strings.Split(block, "\n") {
+		if len(cur) > 0 && esmStartRe.MatchString(line) {
+			stmts = append(stmts, strings.Join(cur, "\n"))
+			cur = nil
+		}
+		cur = append(cur, line)
+	}
+	if len(cur) > 0 {
+		stmts = append(stmts, strings.Join(cur, "\n"))
+	}
+	return stmts
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
+
+// braceDepth returns the nesting depth of {} left at the end of s, skipping
+// JavaScript strings.
+func braceDepth(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\'', '`':
+			if end := strings.IndexByte(s[i+1:], c); end >= 0 {
+				i += end + 1
+			}
+		case '{':
+			depth++
+		case '}':
+			depth--
+		}
+	}
+	return depth
+}
+
+func (a *analyzer) report(line, col int, msg string) {
+	a.diags = append(a.diags, diagnostic{Line: line, Col: col, Message: msg})
+}
+
+// reportAt reports a problem at a byte offset of the blanked source.
+func (a *analyzer) reportAt(pos int, format string, args ...any) {
+	line := 0
+	for line+1 < len(a.lineStarts) && a.lineStarts[line+1] <= pos {
+		line++
+	}
+	a.report(line+1, pos-a.lineStarts[line]+1, fmt.Sprintf(format, args...))
+}
+
+// scan walks the Markdown text for code spans, comments, JSX and expressions.
+func (a *analyzer) scan() {
+	s := a.src
+	var stack []openTag
+
+	for i := 0; i < len(s); {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte("\\`*_{}[]()#+-.!<>|~\"'", s[i+1]) >= 0:
+			i += 2
+		case c == '`':
+			n := backtickRun(s, i)
+			if end := closingCodeSpan(s, i+n, n); end >= 0 {
+				i = end + n
+			} else {
+				i += n
+			}
+		case strings.HasPrefix(s[i:], "<!--"):
+			a.reportAt(i, "HTML comments are not valid MDX, use {/* ... */}")
+			if end := strings.Index(s[i:], "-->"); end >= 0 {
+				i += end + 3
+			} else {
+				i += 4
+			}
+		case c == '<' && (i+1 == len(s) || isJSXSpace(s[i+1]) || s[i+1] >= '0' && s[i+1] <= '9'):
+			// "a < b" and "< 1.10" stay text.
+			i++
+		case c == '<':
+			if m := autolinkRe.FindString(s[i:]); m != "" {
+				a.reportAt(i, "autolink %s is not valid MDX, use [text](url)", m)
+				i += len(m)
+				continue
+			}
+			tag, next, err := parseJSXTag(s, i)
+			if err != "" {
+				a.reportAt(next, "%s", err)
+				i++
+				continue
+			}
+			for _, e := range tag.exprs {
+				a.checkExpression(e[0], e[1])
+			}
+			switch {
+			case tag.selfClosing:
+			case !tag.closing:
+				stack = append(stack, openTag{name: tag.name, pos: i})
+			default:
+				stack = a.closeTag(stack, tag.name, i)
+			}
+			i = next
+		case c == '{':
+			end := matchBrace(s, i)
+			if end < 0 {
+				a.reportAt(i, "unclosed expression: '{' without a matching '}' (escape a literal brace as \\{)")
+				i++
+				continue
+			}
+			a.checkExpression(i+1, end)
+			i = end + 1
+		default:
+			i++
+		}
+	}
+
+	for _, t := range stack {
+		a.reportAt(t.pos, "unclosed <%s>: expected a closing tag </%s> (or write <%s />)", t.name, t.name, t.name)
+	}
+}
+
+// closeTag pops the element closed by </name> at pos.
+func (a *analyzer) closeTag(stack []openTag, name string, pos int) []openTag {
+	for j := len(stack) - 1; j >= 0; j-- {
+		if stack[j].name != name {
+			continue
+		}
+		for _, t := range stack[j+1:] {
+			a.reportAt(t.pos, "unclosed <%s>: expected a closing tag </%s> before </%s>", t.name, t.name, name)
+		}
+		return stack[:j]
+	}
+	a.reportAt(pos, "unexpected closing tag </%s>", name)
+	return stack
+}
+
+// checkExpression reports an expression naming a single variable the page
+// does not import or export; MDX compiles it, but rendering fails.
+func (a *analyzer) checkExpression(start, end int) {
+	if m := identRe.FindStringSubmatch(a.src[start:end]); m != nil && !a.declared[m[1]] {
+		a.reportAt(start-1, "expression {%s} uses %s, which is not imported or exported (escape a literal brace as \\{)", m[1], m[1])
+	}
+}
+
+// closingCodeSpan returns the start of the run of n backticks closing a code
+// span opened before from, or -1. Code spans do not cross blank lines.
+func closingCodeSpan(s string, from, n int) int {
+	limit := len(s)
+	if p := strings.Index(s[from:], "\n\n"); p >= 0 {
+		limit = from + p
+	}
+	if end := closingBacktickRun(s[:limit], from, n); end >= 0 {
+		return end
+	}
+	return -1
+}
+
+// backtickRun returns the length of the run of backticks at s[i].
+func backtickRun(s string, i int) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == '`' {
+		n++
+	}
+	return n
+}
+
+// closingBacktickRun returns the index of the next run of exactly n backticks
+// at or after from, or -1.
+func closingBacktickRun(s string, from, n int) int {
+	for i := from; i < len(s); {
+		if s[i] != '`' {
+			i++
+			continue
+		}
+		m := backtickRun(s, i)
+		if m == n {
+			return i
+		}
+		i += m
+	}
+	return -1
+}
+
+// matchBrace returns the index of the '}' closing the '{' at s[i], or -1. It
+// skips JavaScript strings and comments inside the expression.
+func matchBrace(s string, i int) int {
+	depth := 0
+	for ; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"', '\'', '`':
+			end := strings.IndexByte(s[i+1:], c)
+			if end < 0 {
+				return -1
+			}
+			i += end + 1
+		case '/':
+			if strings.HasPrefix(s[i:], "/*") {
+				end := strings.Index(s[i+2:], "*/")
+				if end < 0 {
+					return -1
+				}
+				i += end + 3
+			}
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// jsxTag is a parsed JSX start or end tag.
+type jsxTag struct {
+	name        string // "" for a fragment
+	closing     bool
+	selfClosing bool
+	exprs       [][2]int // attribute expressions, as [start, end) offsets of their content
+}
+
+func isNameStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '$'
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || c >= '0' && c <= '9' || c == '-'
+}
+
+func isJSXSpace(c byte) bool { return c == ' ' || c == '\t' || c == '\n' || c == '\r' }
+
+// parseJSXTag parses the tag starting with '<' at s[i]. It returns the tag and
+// the offset after it, or an error message and the offset it applies to.
+func parseJSXTag(s string, i int) (jsxTag, int, string) {
+	var t jsxTag
+	start := i
+	i++
+	skip := func() {
+		for i < len(s) && isJSXSpace(s[i]) {
+			i++
+		}
+	}
+	name := func() string {
+		from := i
+		for i < len(s) && (isNameChar(s[i]) || i > from && (s[i] == '.' || s[i] == ':') && i+1 < len(s) && isNameStart(s[i+1])) {
+			i++
+		}
+		return s[from:i]
+	}
+
+	if i < len(s) && s[i] == '/' {
+		t.closing = true
+		i++
+	}
+	if i < len(s) && s[i] == '>' {
+		return t, i + 1, "" // fragment
+	}
+	if i >= len(s) || !isNameStart(s[i]) {
+		what := "end of file"
+		if i < len(s) {
+			what = fmt.Sprintf("%q", s[i])
+		}
+		return t, start, fmt.Sprintf("stray '<' before %s: escape it as \\< or wrap it in a code span", what)
+	}
+	t.name = name()
+
+	for {
+		skip()
+		if i >= len(s) {
+			return t, start, fmt.Sprintf("unterminated tag <%s", t.name)
+		}
+		switch {
+		case s[i] == '>':
+			return t, i + 1, ""
+		case strings.HasPrefix(s[i:], "/>") && !t.closing:
+			t.selfClosing = true
+			return t, i + 2, ""
+		case t.closing:
+			return t, i, fmt.Sprintf("unexpected %q in closing tag </%s>", s[i], t.name)
+		case s[i] == '{':
+			end := matchBrace(s, i)
+			if end < 0 {
+				return t, i, fmt.Sprintf("unclosed attribute expression in <%s>", t.name)
+			}
+			i = end + 1
+		case isNameStart(s[i]):
+			attr := name()
+			skip()
+			if i >= len(s) || s[i] != '=' {
+				continue
+			}
+			i++
+			skip()
+			switch {
+			case i < len(s) && (s[i] == '"' || s[i] == '\''):
+				end := strings.IndexByte(s[i+1:], s[i])
+				if end < 0 {
+					return t, i, fmt.Sprintf("unterminated value of attribute %s in <%s>", attr, t.name)
+				}
+				i += end + 2
+			case i < len(s) && s[i] == '{':
+				end := matchBrace(s, i)
+				if end < 0 {
+					return t, i, fmt.Sprintf("unclosed expression in attribute %s of <%s>", attr, t.name)
+				}
+				t.exprs = append(t.exprs, [2]int{i + 1, end})
+				i = end + 1
+			default:
+				return t, i, fmt.Sprintf("invalid value of attribute %s in <%s>: quote it or use {}", attr, t.name)
+			}
+		default:
+			return t, i, fmt.Sprintf("invalid attribute syntax in <%s> at %q", t.name, s[i])
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func diagnostics(content string) string {
+	var out []string
+	for _, d := range analyzeMDX(content) {
+		out = append(out, d.String())
+	}
+	return strings.Join(out, "\n")
+}
+
+func TestAnalyzeCleanPage(t *testing.T) {
+	in := `---
+title: "A <page> {title}"
+---
+
+import { VersionWarningBanner } from "/snippets/version-warning-banner.jsx"
+import { release_v1_14 } from '/snippets/custom-variables.mdx';
+
+<VersionWarningBanner />
+
+Install {release_v1_14} with ` + "`talosctl <node>`" + ` on Talos < 1.10, escaping \<id> and \{x}.
+
+<Note title="Docker" icon={"info"}>
+  Run:
+
+  ` + "```bash" + `
+  source <(talosctl completion bash) {
+  ` + "```" + `
+</Note>
+
+<img src="/images/a.png" alt="a" />
+{/* a comment */}
+`
+	if got := diagnostics(in); got != "" {
+		t.Errorf("unexpected diagnostics:\n%s", got)
+	}
+}
+
+func TestAnalyzeProblems(t *testing.T) {
+	for _, tc := range []struct{ name, in, want string }{
+		{"placeholder", "get machinestatus <machine-id> now\n", "1:19: unclosed <machine-id>"},
+		{"process substitution", "\tsource <(omnictl completion bash)\n", "1:9: stray '<' before '('"},
+		{"void element", "line<br>break\n", "1:5: unclosed <br>"},
+		{"mismatch", "<Note>\n<Tip>\n</Note>\n", "2:1: unclosed <Tip>: expected a closing tag </Tip> before </Note>"},
+		{"unexpected close", "text</Note>\n", "1:5: unexpected closing tag </Note>"},
+		{"unquoted attribute", "<Card title=Hello />\n", "1:13: invalid value of attribute title in <Card>"},
+		{"bad attribute", "<Card \"x\" />\n", "1:7: invalid attribute syntax in <Card>"},
+		{"unclosed brace", "the value {placeholder is required\n\nnext\n", "1:11: unclosed expression"},
+		{"undefined variable", "version {release}\n", "1:9: expression {release} uses release"},
+		{"html comment", "a <!-- note --> b\n", "1:3: HTML comments are not valid MDX"},
+		{"autolink", "see <https://example.com>\n", "1:5: autolink <https://example.com>"},
+		{"bad import", "import VersionWarningBanner\n\ntext\n", "1:1: invalid import statement"},
+		{"unclosed fence", "text\n\n```yaml\na: 1\n", "3:1: unclosed code fence ```"},
+	} {
+		got := diagnostics(tc.in)
+		if !strings.HasPrefix(got, tc.want) || strings.Contains(got, "\n") {
+			t.Errorf("%s: got diagnostics\n%s\nwant one starting with %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestAnalyzeNormalizedOutputCompiles(t *testing.T) {
+	// Tab-indented examples are unsafe MDX until normalize fences them.
+	in := "To load completions:\n\n\tsource <(omnictl completion bash)\n"
+	if diagnostics(in) == "" {
+		t.Fatal("expected the raw input to have problems")
+	}
+	if got := run(t, in, false); diagnostics(got) != "" {
+		t.Errorf("normalized output has problems:\n%s", diagnostics(got))
+	}
+}
@@ -0,0 +1,442 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Flag list styles of --cobra.
+const (
+	cobraTable      = "table"
+	cobraParamField = "paramfield"
+)
+
+// Subsections of a command in a cobra-generated reference, as written by
+// cobra's GenMarkdown and by restructureCobra. Matching ignores case.
+const (
+	sectionSynopsis  = "Synopsis"
+	sectionUsage     = "Usage"
+	sectionExamples  = "Examples"
+	sectionOptions   = "Options"
+	sectionInherited = "Options inherited from parent commands"
+	sectionSeeAlso   = "See also"
+)
+
+var cobraSections = []string{sectionSynopsis, sectionUsage, sectionExamples, sectionOptions, sectionInherited, sectionSeeAlso}
+
+var (
+	headingRe = regexp.MustCompile(`^(#{1,6})[ \t]+(.*?)[ \t]*$`)
+	// "  -d, --dry-run       Dry run, implies verbose" in a cobra options block.
+	cobraFlagRe = regexp.MustCompile(`^[ \t]*(?:-(\S), )?--([^\s=]+)(?: (\S+))?(?:\s{2,}(.*))?$`)
+	// Only the "(default X)" cobra appends, not one the usage text itself
+	// ends with, such as "(default is to display in raw format)".
+	defaultRe = regexp.MustCompile(`^(.*?)\s*\(default ("[^"]*"|\[[^\]]*\]|[^\s()]+)\)$`)
+	// "* [omnictl](#omnictl)	 - A CLI for accessing Omni API." and the
+	// "- Parent command: [...](#...) - ..." form written back.
+	seeAlsoRe    = regexp.MustCompile(`^[*-] (?:(?:Parent command|Subcommand): )?\[([^\]]+)\]\([^)]*\)(?:\s+-\s*(.*))?$`)
+	shorthandRe  = regexp.MustCompile("^Shorthand `-(\\S)`\\.\\s*")
+	paramFieldRe = regexp.MustCompile(`^<ParamField path="--([^"]+)"(?: type="([^"]*)")?(?: default="([^"]*)")?>$`)
+)
+
+// cobraFlag is one flag of a command.
+type cobraFlag struct {
+	Shorthand string
+	Name      string
+	Type      string
+	Default   string
+	Usage     string
+}
+
+// cobraLink is one entry of a command's "See also" list.
+type cobraLink struct {
+	Name        string
+	Description string
+}
+
+// cobraSection is a subsection restructureCobra does not know, kept as is.
+type cobraSection struct {
+	Heading string
+	Lines   []string
+}
+
+// cobraCommand is the parsed documentation of one command.
+type cobraCommand struct {
+	Name      string
+	Short     []string
+	Synopsis  []string
+	Usage     []string
+	Examples  []string
+	Options   []cobraFlag
+	Inherited []cobraFlag
+	SeeAlso   []cobraLink
+	Extra     []cobraSection
+}
+
+// restructureCobra rewrites a cobra-generated CLI reference (one "## command"
+// section per command, with Synopsis, Options, Options inherited from parent
+// commands and SEE ALSO subsections) as structured MDX: a usage fence, the
+// flags as a table or ParamField list with their type and default, and links
+// to the parent command and subcommands. Anything before the first command is
+// kept. Pages that are not cobra references are returned unchanged, and the
+// output parses back to the same commands, so a second run changes nothing.
+func restructureCobra(lines []string, style string) []string {
+	cmdLevel := cobraCommandLevel(lines)
+	if cmdLevel == 0 {
+		return lines
+	}
+
+	var (
+		out      []string
+		commands []*cobraCommand
+		bodies   [][]string
+		inFence  bool
+	)
+	for _, line := range lines {
+		if fenceRe.MatchString(line) {
+			inFence = !inFence
+		}
+		if m := headingRe.FindStringSubmatch(line); m != nil && !inFence && len(m[1]) == cmdLevel {
+			// --headings puts the command name in a code span.
+			commands = append(commands, &cobraCommand{Name: strings.Trim(m[2], "`")})
+			bodies = append(bodies, nil)
+			continue
+		}
+		if len(commands) == 0 {
+			out = append(out, line)
+			continue
+		}
+		bodies[len(bodies)-1] = append(bodies[len(bodies)-1], line)
+	}
+
+	for i, c := range commands {
+		parseCobraCommand(c, bodies[i], cmdLevel+1)
+	}
+
+	out = trimBlank(out)
+	for _, c := range commands {
+		if len(out) > 0 {
+			out = append(out, "")
+		}
+		out = append(out, renderCobraCommand(c, cmdLevel, style)...)
+	}
+	return append(out, "")
+}
+
+// cobraCommandLevel returns the heading level of the command sections: one
+// above the level of the subsections cobra writes. It returns 0 if lines are
+// not a cobra reference.
+func cobraCommandLevel(lines []string) int {
+	sub := 0
+	inFence := false
+	for _, line := range lines {
+		if fenceRe.MatchString(line) {
+			inFence = !inFence
+		}
+		m := headingRe.FindStringSubmatch(line)
+		if m == nil || inFence || !isCobraSection(m[2]) {
+			continue
+		}
+		if level := len(m[1]); sub == 0 || level < sub {
+			sub = level
+		}
+	}
+	if sub < 2 {
+		return 0
+	}
+	return sub - 1
+}
+
+// isCobraSection reports whether a heading is one of the known subsections.
+func isCobraSection(heading string) bool {
+	for _, s := range cobraSections {
+		if strings.EqualFold(heading, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCobraCommand fills c from the lines following its heading.
+func parseCobraCommand(c *cobraCommand, body []string, subLevel int) {
+	current := ""
+	var intro []string
+	sections := map[string][]string{}
+	var order []string
+	inFence := false
+	for _, line := range body {
+		if fenceRe.MatchString(line) {
+			inFence = !inFence
+		}
+		if m := headingRe.FindStringSubmatch(line); m != nil && !inFence && len(m[1]) == subLevel {
+			current = m[2]
+			for _, s := range cobraSections {
+				if strings.EqualFold(current, s) {
+					current = s
+				}
+			}
+			if _, ok := sections[current]; !ok {
+				order = append(order, current)
+			}
+			sections[current] = []string{}
+			continue
+		}
+		if current == "" {
+			intro = append(intro, line)
+		} else {
+			sections[current] = append(sections[current], line)
+		}
+	}
+
+	// cobra writes the usage fence at the end of the synopsis, or right
+	// after the short description if the command has no long description.
+	c.Short, c.Usage = splitUsage(intro, c.Name)
+	synopsis, usage := splitUsage(sections[sectionSynopsis], c.Name)
+	c.Synopsis = synopsis
+	if usage != nil {
+		c.Usage = usage
+	}
+	if u := fencedLines(sections[sectionUsage]); u != nil {
+		c.Usage = u
+	}
+	c.Examples = trimBlank(sections[sectionExamples])
+	c.Options = parseCobraFlags(sections[sectionOptions])
+	c.Inherited = parseCobraFlags(sections[sectionInherited])
+	for _, line := range sections[sectionSeeAlso] {
+		if m := seeAlsoRe.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			c.SeeAlso = append(c.SeeAlso, cobraLink{Name: m[1], Description: strings.TrimSpace(m[2])})
+		}
+	}
+	for _, name := range order {
+		if !isCobraSection(name) {
+			c.Extra = append(c.Extra, cobraSection{Heading: name, Lines: trimBlank(sections[name])})
+		}
+	}
+}
+
+// splitUsage removes the last fenced block of lines if it holds the usage
+// line of command, and returns the remaining lines and the usage.
+func splitUsage(lines []string, command string) (rest, usage []string) {
+	lines = trimBlank(lines)
+	if len(lines) < 3 || !fenceRe.MatchString(lines[len(lines)-1]) {
+		return lines, nil
+	}
+	start := len(lines) - 2
+	for start >= 0 && !fenceRe.MatchString(lines[start]) {
+		start--
+	}
+	if start < 0 || start == len(lines)-2 || !strings.HasPrefix(strings.TrimSpace(lines[start+1]), command) {
+		return lines, nil
+	}
+	return trimBlank(lines[:start]), lines[start+1 : len(lines)-1]
+}
+
+// fencedLines returns the content of the first fenced block of lines.
+func fencedLines(lines []string) []string {
+	start := -1
+	for i, line := range lines {
+		if !fenceRe.MatchString(line) {
+			continue
+		}
+		if start < 0 {
+			start = i
+			continue
+		}
+		return lines[start+1 : i]
+	}
+	return nil
+}
+
+// parseCobraFlags parses an options section: cobra's fenced flag list, or the
+// table or ParamField list restructureCobra writes.
+func parseCobraFlags(lines []string) []cobraFlag {
+	if block := fencedLines(lines); block != nil {
+		var flags []cobraFlag
+		for _, line := range block {
+			m := cobraFlagRe.FindStringSubmatch(line)
+			if m == nil {
+				// A continuation of the previous flag's usage.
+				if n := len(flags); n > 0 && strings.TrimSpace(line) != "" {
+					flags[n-1].Usage = strings.TrimSpace(flags[n-1].Usage + " " + strings.TrimSpace(line))
+				}
+				continue
+			}
+			f := cobraFlag{Shorthand: m[1], Name: m[2], Type: m[3], Usage: strings.TrimSpace(m[4])}
+			if f.Type == "" {
+				f.Type = "bool"
+			}
+			flags = append(flags, f)
+		}
+		for i := range flags {
+			if d := defaultRe.FindStringSubmatch(flags[i].Usage); d != nil {
+				flags[i].Usage, flags[i].Default = d[1], strings.Trim(d[2], `"`)
+			}
+		}
+		return flags
+	}
+
+	var flags []cobraFlag
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if m := paramFieldRe.FindStringSubmatch(line); m != nil {
+			f := cobraFlag{Name: m[1], Type: html.UnescapeString(m[2]), Default: html.UnescapeString(m[3])}
+			var usage []string
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "</ParamField>"; i++ {
+				usage = append(usage, strings.TrimSpace(lines[i]))
+			}
+			f.Usage = strings.Join(usage, " ")
+			if s := shorthandRe.FindStringSubmatch(f.Usage); s != nil {
+				f.Shorthand, f.Usage = s[1], f.Usage[len(s[0]):]
+			}
+			flags = append(flags, f)
+			continue
+		}
+		cells := tableCells(line)
+		if len(cells) != 4 || !strings.Contains(cells[0], "`--") {
+			continue
+		}
+		f := cobraFlag{Type: strings.Trim(cells[1], "`"), Default: strings.Trim(cells[2], "`"), Usage: cells[3]}
+		for _, name := range strings.Split(cells[0], ",") {
+			name = strings.Trim(strings.TrimSpace(name), "`")
+			if long, ok := strings.CutPrefix(name, "--"); ok {
+				f.Name = long
+			} else {
+				f.Shorthand = strings.TrimPrefix(name, "-")
+			}
+		}
+		flags = append(flags, f)
+	}
+	return flags
+}
+
+// tableCells splits a Markdown table row at unescaped pipes and unescapes
+// them in the cells.
+func tableCells(line string) []string {
+	if !strings.HasPrefix(line, "|") || !strings.HasSuffix(line, "|") || len(line) < 2 {
+		return nil
+	}
+	var cells []string
+	var cell strings.Builder
+	row := line[1 : len(line)-1]
+	for i := 0; i < len(row); i++ {
+		switch {
+		case row[i] == '\\' && i+1 < len(row) && row[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case row[i] == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteByte(row[i])
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// renderCobraCommand writes one command as structured MDX.
+func renderCobraCommand(c *cobraCommand, level int, style string) []string {
+	sub := strings.Repeat("#", level+1) + " "
+	out := []string{strings.Repeat("#", level) + " " + c.Name}
+	section := func(heading string, body []string) {
+		if len(body) > 0 {
+			out = append(out, "", sub+heading, "")
+			out = append(out, body...)
+		}
+	}
+
+	if len(c.Short) > 0 {
+		out = append(out, "")
+		out = append(out, c.Short...)
+	}
+	section(sectionSynopsis, c.Synopsis)
+	if len(c.Usage) > 0 {
+		section(sectionUsage, append(append([]string{"```bash"}, c.Usage...), "```"))
+	}
+	section(sectionExamples, c.Examples)
+	section(sectionOptions, renderCobraFlags(c.Options, style))
+	section(sectionInherited, renderCobraFlags(c.Inherited, style))
+
+	var links []string
+	for _, l := range c.SeeAlso {
+		kind := ""
+		switch {
+		case l.Name == parentCommand(c.Name):
+			kind = "Parent command: "
+		case parentCommand(l.Name) == c.Name:
+			kind = "Subcommand: "
+		}
+		entry := "- " + kind + "[" + l.Name + "](#" + commandAnchor(l.Name) + ")"
+		if l.Description != "" {
+			entry += " - " + l.Description
+		}
+		links = append(links, entry)
+	}
+	section(sectionSeeAlso, links)
+
+	for _, s := range c.Extra {
+		section(s.Heading, s.Lines)
+	}
+	return out
+}
+
+// renderCobraFlags renders flags as a table or a ParamField list.
+func renderCobraFlags(flags []cobraFlag, style string) []string {
+	if len(flags) == 0 {
+		return nil
+	}
+	var out []string
+	if style == cobraParamField {
+		for _, f := range flags {
+			attrs := fmt.Sprintf(`path="--%s" type="%s"`, f.Name, html.EscapeString(f.Type))
+			if f.Default != "" {
+				attrs += fmt.Sprintf(` default="%s"`, html.EscapeString(f.Default))
+			}
+			usage := escapeInlineMDX(f.Usage)
+			if f.Shorthand != "" {
+				usage = "Shorthand `-" + f.Shorthand + "`. " + usage
+			}
+			out = append(out, "<ParamField "+attrs+">", "  "+usage, "</ParamField>")
+		}
+		return out
+	}
+
+	out = append(out, "| Flag | Type | Default | Description |", "| --- | --- | --- | --- |")
+	for _, f := range flags {
+		name := "`--" + f.Name + "`"
+		if f.Shorthand != "" {
+			name = "`-" + f.Shorthand + "`, " + name
+		}
+		def := ""
+		if f.Default != "" {
+			def = "`" + strings.ReplaceAll(f.Default, "|", `\|`) + "`"
+		}
+		usage := strings.ReplaceAll(escapeInlineMDX(f.Usage), "|", `\|`)
+		out = append(out, fmt.Sprintf("| %s | `%s` | %s | %s |", name, f.Type, def, usage))
+	}
+	return out
+}
+
+// parentCommand returns the command a command is a subcommand of.
+func parentCommand(name string) string {
+	if i := strings.LastIndex(name, " "); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// commandAnchor returns the heading anchor of a command section.
+func commandAnchor(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), " ", "-")
+}
+
+// trimBlank drops leading and trailing blank lines.
+func trimBlank(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
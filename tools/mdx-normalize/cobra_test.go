@@ -0,0 +1,146 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// cobraDoc is a two-command page as written by `omnictl docs`.
+const cobraDoc = `---
+title: omnictl CLI
+---
+
+## omnictl apply
+
+Create or update resource
+
+### Synopsis
+
+Create or update resources using YAML file(s) as input.
+
+` + "```" + `
+omnictl apply [flags]
+` + "```" + `
+
+### Options
+
+` + "```" + `
+  -d, --dry-run            Dry run, implies verbose
+  -f, --file string        Resource file or directory to load and apply
+      --log-format string   log format (raw, omni, dmesg) to display (default is to display in raw format) (default "raw")
+      --namespace string    resource namespace (default is to use default namespace per resource)
+      --timeout duration   How long to wait (default 5m0s)
+` + "```" + `
+
+### Options inherited from parent commands
+
+` + "```" + `
+      --context string   The context to be used.
+` + "```" + `
+
+### SEE ALSO
+
+* [omnictl](#omnictl)	 - A CLI for accessing Omni API.
+
+## omnictl
+
+A CLI for accessing Omni API.
+
+### Options
+
+` + "```" + `
+      --context string   The context to be used.
+  -h, --help             help for omnictl
+` + "```" + `
+
+### SEE ALSO
+
+* [omnictl apply](#omnictl-apply)	 - Create or update resource
+`
+
+func cobra(t *testing.T, in, style string) string {
+	t.Helper()
+	return string(normalizeBytes([]byte(in), options{Cobra: style}))
+}
+
+func TestCobraTable(t *testing.T) {
+	got := cobra(t, cobraDoc, cobraTable)
+	for _, want := range []string{
+		"### Synopsis\n\nCreate or update resources using YAML file(s) as input.\n\n### Usage\n\n```bash\nomnictl apply [flags]\n```\n",
+		"| `-d`, `--dry-run` | `bool` |  | Dry run, implies verbose |\n",
+		"| `--timeout` | `duration` | `5m0s` | How long to wait |\n",
+		"| `--log-format` | `string` | `raw` | log format (raw, omni, dmesg) to display (default is to display in raw format) |\n",
+		"| `--namespace` | `string` |  | resource namespace (default is to use default namespace per resource) |\n",
+		"### Options inherited from parent commands\n\n| Flag | Type | Default | Description |\n| --- | --- | --- | --- |\n| `--context` | `string` |  | The context to be used. |\n",
+		"### See also\n\n- Parent command: [omnictl](#omnictl) - A CLI for accessing Omni API.\n",
+		"- Subcommand: [omnictl apply](#omnictl-apply) - Create or update resource\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if !strings.HasPrefix(got, "---\ntitle: omnictl CLI\n---\n\n## omnictl apply\n") {
+		t.Errorf("frontmatter not kept:\n%s", got)
+	}
+}
+
+func TestCobraParamField(t *testing.T) {
+	got := cobra(t, cobraDoc, cobraParamField)
+	for _, want := range []string{
+		"<ParamField path=\"--file\" type=\"string\">\n  Shorthand `-f`. Resource file or directory to load and apply\n</ParamField>\n",
+		"<ParamField path=\"--timeout\" type=\"duration\" default=\"5m0s\">\n  How long to wait\n</ParamField>\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %q:\n%s", want, got)
+		}
+	}
+	if d := diagnostics(got); d != "" {
+		t.Errorf("output does not compile:\n%s", d)
+	}
+}
+
+func TestCobraIdempotent(t *testing.T) {
+	for _, style := range []string{cobraTable, cobraParamField} {
+		once := cobra(t, cobraDoc, style)
+		if twice := cobra(t, once, style); twice != once {
+			t.Errorf("%s: second run changed the output:\n%s", style, unifiedDiff("once", "twice", []byte(once), []byte(twice)))
+		}
+	}
+	// --headings puts the command names in code spans; they are read back.
+	opts := options{Cobra: cobraTable, Headings: true}
+	once := string(normalizeBytes([]byte(cobraDoc), opts))
+	if !strings.Contains(once, "## `omnictl apply`\n") {
+		t.Errorf("command heading not in a code span:\n%s", once)
+	}
+	if twice := string(normalizeBytes([]byte(once), opts)); twice != once {
+		t.Errorf("with --headings, second run changed the output:\n%s", unifiedDiff("once", "twice", []byte(once), []byte(twice)))
+	}
+	// Switching style reads the other style back.
+	if got, want := cobra(t, cobra(t, cobraDoc, cobraTable), cobraParamField), cobra(t, cobraDoc, cobraParamField); got != want {
+		t.Errorf("table to paramfield differs from a direct run:\n%s", unifiedDiff("direct", "converted", []byte(want), []byte(got)))
+	}
+}
+
+func TestCobraIgnoresOtherPages(t *testing.T) {
+	in := "## Install\n\nRun:\n\n```bash\nomnictl apply -f x.yaml\n```\n"
+	if got := cobra(t, in, cobraTable); got != in {
+		t.Errorf("non-cobra page changed:\n%s", got)
+	}
+}
+
+func TestCobraOmniReference(t *testing.T) {
+	data, err := os.ReadFile("../../public/omni/reference/cli.mdx")
+	if err != nil {
+		t.Skip(err)
+	}
+	for _, style := range []string{cobraTable, cobraParamField} {
+		once := cobra(t, string(data), style)
+		if twice := cobra(t, once, style); twice != once {
+			t.Errorf("%s: second run changed the output", style)
+		}
+		if d := diagnostics(once); d != "" {
+			t.Errorf("%s: output does not compile:\n%s", style, d)
+		}
+	}
+}
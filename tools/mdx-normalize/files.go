@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// options are the settings of one run over a set of files.
+type options struct {
+	StripHR bool
+	Check   bool   // report files that would change instead of writing them
+	Lint    bool   // report MDX compile problems instead of normalizing
+	Cobra   string // restructure cobra CLI references, with this flag list style
+	Label   bool   // add a detected language hint to existing unlabelled fences
+	// Headings re-levels headings and rewrites them in sentence case, keeping
+	// the words in Exceptions.
+	Headings   bool
+	Exceptions map[string]bool
+	Jobs       int
+}
+
+// result is the outcome of normalizing one file.
+type result struct {
+	Path    string
+	Changed bool
+	Diff    string // unified diff, only in check mode
+	Diags   []diagnostic
+	Err     error
+}
+
+// normalizeBytes normalizes the content of one file. A trailing newline is
+// preserved: strings.Split leaves a final "" element that Join turns back
+// into the closing newline.
+func normalizeBytes(data []byte, opts options) []byte {
+	lines := normalize(strings.Split(string(data), "\n"), opts.StripHR)
+	if opts.Label {
+		lines = labelFences(lines)
+	}
+	if opts.Cobra != "" {
+		lines = restructureCobra(lines, opts.Cobra)
+	}
+	if opts.Headings {
+		lines = normalizeHeadings(lines, opts.Exceptions)
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// isDocFile reports whether a file found while walking a directory is
+// normalized.
+func isDocFile(path string) bool {
+	ext := filepath.Ext(path)
+	return ext == ".mdx" || ext == ".md"
+}
+
+// collectFiles expands the arguments into the list of files to normalize.
+// Files are taken as given; directories are walked for .md and .mdx files.
+// Each file is listed once, in argument and walk order.
+func collectFiles(args []string) ([]string, error) {
+	var files []string
+	seen := map[string]bool{}
+	add := func(path string) {
+		if !seen[filepath.Clean(path)] {
+			seen[filepath.Clean(path)] = true
+			files = append(files, path)
+		}
+	}
+
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			add(arg)
+			continue
+		}
+		err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && isDocFile(path) {
+				add(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// processFiles normalizes every file, up to opts.Jobs at a time, and returns
+// the results in the order of files.
+func processFiles(files []string, opts options) []result {
+	results := make([]result, len(files))
+	sem := make(chan struct{}, max(opts.Jobs, 1))
+	var wg sync.WaitGroup
+	for i, path := range files {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = processFile(path, opts)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// processFile normalizes one file in place, or in check mode only compares it
+// with its normalized form. Unchanged files are never rewritten.
+func processFile(path string, opts options) result {
+	r := result{Path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	if opts.Lint {
+		r.Diags = analyzeMDX(string(data))
+		return r
+	}
+	out := normalizeBytes(data, opts)
+	if bytes.Equal(data, out) {
+		return r
+	}
+	r.Changed = true
+	if opts.Check {
+		r.Diff = unifiedDiff("a/"+filepath.ToSlash(path), "b/"+filepath.ToSlash(path), data, out)
+		return r
+	}
+	r.Err = writeFileAtomic(path, out)
+	return r
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the same
+// directory, so readers never see a partially written file. The file keeps its
+// permissions.
+func writeFileAtomic(path string, data []byte) error {
+	mode := fs.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// report prints the results to w, and errors to errW, and returns the exit
+// code: 1 if a file could not be processed, has MDX problems or, in check
+// mode, would change.
+func report(w, errW io.Writer, results []result, opts options) int {
+	code, changed, problems := 0, 0, 0
+	for _, r := range results {
+		switch {
+		case r.Err == nil && len(r.Diags) > 0:
+			for _, d := range r.Diags {
+				fmt.Fprintf(w, "%s:%s\n", r.Path, d)
+			}
+			problems += len(r.Diags)
+			code = 1
+		case r.Err != nil:
+			fmt.Fprintf(errW, "mdx-normalize: %v\n", r.Err)
+			code = 1
+		case r.Changed && opts.Check:
+			fmt.Fprintf(w, "would change: %s\n", r.Path)
+			fmt.Fprint(w, r.Diff)
+			changed++
+			code = 1
+		case r.Changed:
+			fmt.Fprintf(w, "normalized: %s\n", r.Path)
+			changed++
+		}
+	}
+	if problems > 0 {
+		fmt.Fprintf(w, "%d MDX problem(s) found\n", problems)
+	}
+	if opts.Check && changed > 0 {
+		fmt.Fprintf(w, "%d of %d file(s) are not normalized\n", changed, len(results))
+	}
+	return code
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	cleanDoc = "# Clean\n\nNothing to do.\n"
+	dirtyDoc = "Run:\n\n\tsource <(omnictl completion bash)\n"
+	fixedDoc = "Run:\n\n```bash\nsource <(omnictl completion bash)\n```\n"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestCollectFiles(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"a.mdx":       cleanDoc,
+		"sub/b.md":    cleanDoc,
+		"sub/c.txt":   dirtyDoc,
+		"sub/d/e.mdx": cleanDoc,
+	})
+	files, err := collectFiles([]string{dir, filepath.Join(dir, "a.mdx"), filepath.Join(dir, "sub", "c.txt")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range files {
+		rel, _ := filepath.Rel(dir, f)
+		got = append(got, filepath.ToSlash(rel))
+	}
+	// Directories only yield .md and .mdx files; explicit files are kept once.
+	if want := "a.mdx sub/b.md sub/d/e.mdx sub/c.txt"; strings.Join(got, " ") != want {
+		t.Errorf("got %v, want %s", got, want)
+	}
+}
+
+func TestProcessFilesInPlace(t *testing.T) {
+	dir := writeTree(t, map[string]string{"clean.mdx": cleanDoc, "dirty.mdx": dirtyDoc})
+	if err := os.Chmod(filepath.Join(dir, "dirty.mdx"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	files, err := collectFiles([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := options{Jobs: 4}
+	var out, errOut bytes.Buffer
+	if code := report(&out, &errOut, processFiles(files, opts), opts); code != 0 {
+		t.Fatalf("exit code %d: %s", code, errOut.String())
+	}
+	if got := readFile(t, filepath.Join(dir, "dirty.mdx")); got != fixedDoc {
+		t.Errorf("dirty.mdx not normalized:\n%s", got)
+	}
+	if !strings.Contains(out.String(), "normalized: "+filepath.Join(dir, "dirty.mdx")) || strings.Contains(out.String(), "clean.mdx") {
+		t.Errorf("unexpected report:\n%s", out.String())
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "dirty.mdx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Errorf("mode changed to %v", info.Mode().Perm())
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Errorf("temporary files left behind: %v", entries)
+	}
+}
+
+func TestCheckDoesNotWrite(t *testing.T) {
+	dir := writeTree(t, map[string]string{"clean.mdx": cleanDoc, "dirty.mdx": dirtyDoc})
+	files, err := collectFiles([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := options{Check: true, Jobs: 2}
+	var out, errOut bytes.Buffer
+	if code := report(&out, &errOut, processFiles(files, opts), opts); code != 1 {
+		t.Errorf("exit code %d, want 1", code)
+	}
+	if got := readFile(t, filepath.Join(dir, "dirty.mdx")); got != dirtyDoc {
+		t.Errorf("check mode wrote dirty.mdx:\n%s", got)
+	}
+	for _, s := range []string{"would change: " + filepath.Join(dir, "dirty.mdx"), "-\tsource <(omnictl completion bash)\n", "+```bash\n", "1 of 2 file(s) are not normalized"} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("report missing %q:\n%s", s, out.String())
+		}
+	}
+
+	if code := report(&out, &errOut, processFiles(files[:1], opts), opts); code != 0 {
+		t.Errorf("clean file: exit code %d, want 0", code)
+	}
+}
+
+func TestFilterCheck(t *testing.T) {
+	var out bytes.Buffer
+	if code := filter(strings.NewReader(dirtyDoc), &out, options{Check: true}); code != 1 || !strings.HasPrefix(out.String(), "--- a/-\n+++ b/-\n") {
+		t.Errorf("exit code %d, output:\n%s", code, out.String())
+	}
+	out.Reset()
+	if code := filter(strings.NewReader(dirtyDoc), &out, options{}); code != 0 || out.String() != fixedDoc {
+		t.Errorf("exit code %d, output:\n%s", code, out.String())
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	codeSpanRe = regexp.MustCompile("`[^`]*`")
+	linkTextRe = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	// A word of a command line: "cluster", "kubernetes-ca", "v1".
+	commandWordRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+	plainCapRe    = regexp.MustCompile(`^[A-Z][a-z]+$`)
+	// An explicit anchor line, as written for heading aliases.
+	anchorLineRe = regexp.MustCompile(`^<a (?:name|id)="[^"<>]*"></a>$`)
+)
+
+// loadExceptions reads a list of words that keep their capitalization in a
+// heading, one per line with # comments, in the format of the style checker's
+// exceptions.txt. The words are returned lowercased.
+func loadExceptions(path string) (map[string]bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	set := map[string]bool{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			set[strings.ToLower(line)] = true
+		}
+	}
+	return set, nil
+}
+
+// normalizeHeadings re-levels the headings of a page to a contiguous
+// hierarchy starting at H2 (the frontmatter title is the H1), puts a command
+// name that starts a heading in a code span, and rewrites the rest of the
+// heading in sentence case, keeping the words in exceptions. When a heading's
+// anchor changes, an alias anchor is written before it so links to the old
+// anchor keep working.
+func normalizeHeadings(lines []string, exceptions map[string]bool) []string {
+	out := make([]string, 0, len(lines))
+	i := 0
+	if len(lines) > 0 && isFrontmatterDelim(lines[0]) {
+		out = append(out, lines[0])
+		for i = 1; i < len(lines); i++ {
+			out = append(out, lines[i])
+			if isFrontmatterDelim(lines[i]) {
+				i++
+				break
+			}
+		}
+	}
+
+	// stack maps the levels of the enclosing headings in the source to their
+	// new levels.
+	type level struct{ from, to int }
+	var stack []level
+	// Code also comes as a template literal, as in
+	// <CodeBlock lang="yaml">{`...`}</CodeBlock>.
+	inFence, inTemplate := false, false
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		switch trimmed := strings.TrimSpace(line); {
+		case !inTemplate && fenceRe.MatchString(line):
+			inFence = !inFence
+		case !inFence && !inTemplate && strings.HasSuffix(trimmed, "{`"):
+			inTemplate = true
+		case inTemplate && strings.HasPrefix(trimmed, "`}"):
+			inTemplate = false
+		}
+		m := headingRe.FindStringSubmatch(line)
+		if m == nil || inFence || inTemplate {
+			out = append(out, line)
+			continue
+		}
+
+		from := len(m[1])
+		for len(stack) > 0 && stack[len(stack)-1].from >= from {
+			stack = stack[:len(stack)-1]
+		}
+		to := 2
+		if len(stack) > 0 {
+			to = min(stack[len(stack)-1].to+1, 6)
+		}
+		stack = append(stack, level{from, to})
+
+		text := sentenceCase(codeCommand(m[2]), exceptions)
+		if alias, ok := aliasLine(m[2], text); ok {
+			out = append(out, alias, "")
+		}
+		out = append(out, strings.Repeat("#", to)+" "+text)
+	}
+	return out
+}
+
+// codeCommand puts a command that starts a heading ("talosctl etcd members",
+// any program named *ctl followed by its subcommands) in a code span.
+func codeCommand(text string) string {
+	words := strings.Fields(text)
+	if len(words) == 0 || !strings.HasSuffix(words[0], "ctl") || !commandWordRe.MatchString(words[0]) {
+		return text
+	}
+	n := 1
+	for n < len(words) && commandWordRe.MatchString(words[n]) && !isProse(words[n]) {
+		n++
+	}
+	return strings.Join(append([]string{"`" + strings.Join(words[:n], " ") + "`"}, words[n:]...), " ")
+}
+
+// isProse reports whether a lowercase word more likely continues a sentence
+// than a command, as in "talosctl reference" or "kubectl and helm".
+func isProse(word string) bool {
+	switch word {
+	case "a", "an", "and", "as", "for", "in", "is", "of", "on", "or", "the", "to", "with", "reference", "commands":
+		return true
+	}
+	return false
+}
+
+// sentenceCase lowercases the capitalized words of a heading outside code
+// spans and links, except the first word, words after the end of a sentence,
+// and exceptions. ALL-CAPS and mixed-case words are names and are kept, as
+// the style checker does.
+func sentenceCase(text string, exceptions map[string]bool) string {
+	var b strings.Builder
+	first, sentenceEnd := true, false
+	rest := text
+	for rest != "" {
+		loc := codeSpanRe.FindStringIndex(rest)
+		if l := linkTextRe.FindStringIndex(rest); l != nil && (loc == nil || l[0] < loc[0]) {
+			loc = l
+		}
+		prose := rest
+		if loc != nil {
+			prose = rest[:loc[0]]
+		}
+		for _, w := range strings.SplitAfter(prose, " ") {
+			core := strings.TrimFunc(strings.TrimSpace(w), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+			core = strings.TrimSuffix(core, "'s")
+			if core == "" {
+				b.WriteString(w)
+				continue
+			}
+			if !first && !sentenceEnd && plainCapRe.MatchString(core) && !exceptions[strings.ToLower(core)] {
+				k := strings.Index(w, core)
+				w = w[:k] + strings.ToLower(core[:1]) + w[k+1:]
+			}
+			b.WriteString(w)
+			first = false
+			sentenceEnd = endsSentence(strings.TrimSpace(w))
+		}
+		if loc == nil {
+			break
+		}
+		// A code span or link counts as a word of the sentence.
+		b.WriteString(rest[loc[0]:loc[1]])
+		first, sentenceEnd = false, false
+		rest = rest[loc[1]:]
+	}
+	return b.String()
+}
+
+// endsSentence reports whether a word ends a sentence, so the next word keeps
+// its capital.
+func endsSentence(w string) bool {
+	w = strings.TrimRight(w, `"')`)
+	return strings.HasSuffix(w, ".") || strings.HasSuffix(w, ":") ||
+		strings.HasSuffix(w, "?") || strings.HasSuffix(w, "!")
+}
+
+// headingSlug returns the anchor Mintlify gives a heading: the text without
+// Markdown, lowercased, with punctuation other than "-" and "_" dropped and
+// spaces turned into hyphens.
+func headingSlug(text string) string {
+	text = linkTextRe.ReplaceAllString(text, "$1")
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// aliasLine returns the anchor line that keeps the anchor of a heading
+// reachable after its text changed from old to new, and whether one is
+// needed.
+func aliasLine(old, new string) (string, bool) {
+	from, to := headingSlug(old), headingSlug(new)
+	if from == to || from == "" {
+		return "", false
+	}
+	return `<a name="` + from + `"></a>`, true
+}
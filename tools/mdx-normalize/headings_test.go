@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+var testExceptions = map[string]bool{"talos": true, "kubespan": true}
+
+func headings(in string) string {
+	return strings.Join(normalizeHeadings(strings.Split(in, "\n"), testExceptions), "\n")
+}
+
+func TestHeadingLevels(t *testing.T) {
+	in := "---\ntitle: A\n---\n\n# Top\n\n#### Linux:\n\n##### Deep\n\n### Sibling\n\n## Next\n\n```bash\n# not a heading\n```\n\n<CodeBlock lang=\"yaml\">\n{`\n# not a heading either\n`}\n</CodeBlock>\n"
+	want := "---\ntitle: A\n---\n\n## Top\n\n### Linux:\n\n#### Deep\n\n### Sibling\n\n### Next\n\n```bash\n# not a heading\n```\n\n<CodeBlock lang=\"yaml\">\n{`\n# not a heading either\n`}\n</CodeBlock>\n"
+	if got := headings(in); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestHeadingText(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"Install Talos On Bare Metal", "Install Talos on bare metal"},
+		{"omnictl cluster delete", "`omnictl cluster delete`"},
+		{"talosctl Reference", "`talosctl` reference"},
+		{"talosctl reference", "`talosctl` reference"},
+		{"Using `Talos Linux` With KubeSpan", "Using `Talos Linux` with KubeSpan"},
+		{"Step 1: Check The Requirements", "Step 1: Check the requirements"},
+		{"Configure the GPU Operator", "Configure the GPU operator"},
+		{"See [Network Config](#network) First", "See [Network Config](#network) first"},
+		{"Options Inherited From Parent Commands", "Options inherited from parent commands"},
+	} {
+		if got := sentenceCase(codeCommand(tc.in), testExceptions); got != tc.want {
+			t.Errorf("%q: got %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestHeadingsIdempotent(t *testing.T) {
+	in := "## omnictl apply\n\n#### Synopsis Text\n\nBody.\n"
+	once := headings(in)
+	if strings.Contains(once, "<a name") {
+		t.Errorf("anchor kept, yet an alias was written:\n%s", once)
+	}
+	if twice := headings(once); twice != once {
+		t.Errorf("second run changed the output:\n%s", twice)
+	}
+	// The normalize pass keeps alias lines, so the full pipeline is stable.
+	withAlias := "<a name=\"old-anchor\"></a>\n\n## New anchor\n"
+	if got := string(normalizeBytes([]byte(withAlias), options{Headings: true})); got != withAlias {
+		t.Errorf("alias line changed:\n%s", got)
+	}
+}
+
+func TestHeadingSlugAndAlias(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"`omnictl cluster delete`", "omnictl-cluster-delete"},
+		{"Step 1: Check the requirements", "step-1-check-the-requirements"},
+		{"See [Network Config](#network) first", "see-network-config-first"},
+		{"Resource viewer (`F4`/`F3`)", "resource-viewer-f4f3"},
+	} {
+		if got := headingSlug(tc.in); got != tc.want {
+			t.Errorf("headingSlug(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+	if _, ok := aliasLine("Install Talos On Bare Metal", "Install Talos on bare metal"); ok {
+		t.Error("alias written for a change of case only")
+	}
+	if got, ok := aliasLine("Linux/macOS", "Linux or macOS"); !ok || got != `<a name="linuxmacos"></a>` {
+		t.Errorf("got %q, %v", got, ok)
+	}
+}
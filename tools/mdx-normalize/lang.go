@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// Language hints detectLanguage can choose.
+const (
+	langShell      = "bash"
+	langPowerShell = "powershell"
+	langYAML       = "yaml"
+	langJSON       = "json"
+	langGo         = "go"
+	langText       = "text"
+)
+
+// minLanguageConfidence is the share of a block's lines that must look like a
+// language for the block to be labelled with it. Anything less sure, and a
+// tie between two languages, is labelled text.
+const minLanguageConfidence = 0.75
+
+var (
+	bareFenceRe = regexp.MustCompile("^([ ]*)(```+)[ \t]*$")
+	commentRe   = regexp.MustCompile(`^#(\s|$)`)
+	// "VAR=value cmd" and "sudo cmd" prefixes of a shell command.
+	envPrefixRe = regexp.MustCompile(`^(?:[A-Za-z_][A-Za-z0-9_]*=\S*\s+)+`)
+	cmdletRe    = regexp.MustCompile(`\b(?:Get|Set|New|Remove|Invoke|Out|Add|Import|Write)-[A-Z][A-Za-z]+\b`)
+	yamlLineRe  = regexp.MustCompile(`^(?:- )*(?:"[^"]*"|'[^']*'|[A-Za-z0-9_.\-/]+):(?:\s|$)|^- \S|^-$|^---$|^\.\.\.$`)
+	goStrongRe  = regexp.MustCompile(`(?m)^(?:package \w+$|import [("]|func[ (]|type \w+ (?:struct|interface)\b)|:=`)
+	goLineRe    = regexp.MustCompile(`^(?:package|import|func|type|var|const|if|for|switch|case|default|return|defer|go)\b|^//|^[})\]]|[{(,]$|:=|^\w+(?:\.\w+)*\(.*\)$|^"[^"]*",?$`)
+	// A flag line of cobra's options block, which is help text, not a command.
+	flagLineRe = regexp.MustCompile(`^(?:-\S, )?--\S`)
+)
+
+// shellCommands are the programs that start the commands of the docs. Any
+// "*ctl" program counts as well.
+var shellCommands = map[string]bool{
+	"apt": true, "apt-get": true, "bash": true, "brew": true, "cat": true,
+	"cd": true, "chmod": true, "cp": true, "crane": true, "curl": true,
+	"dnf": true, "docker": true, "echo": true, "export": true, "git": true,
+	"go": true, "grep": true, "helm": true, "jq": true, "ls": true,
+	"make": true, "mkdir": true, "mv": true, "openssl": true, "rm": true,
+	"scp": true, "sed": true, "sh": true, "source": true, "ssh": true,
+	"sudo": true, "tar": true, "terraform": true, "wget": true, "yq": true,
+	"zsh": true, "fish": true, "talosctl": true, "omnictl": true,
+	"kubectl": true, "systemctl": true, "qemu-img": true, "xz": true,
+}
+
+// detectLanguage returns the language hint of a code block from its content,
+// or text if no language is likely enough.
+func detectLanguage(block []string) string {
+	var lines []string
+	for _, line := range block {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimSpace(line))
+		}
+	}
+	if len(lines) == 0 {
+		return langText
+	}
+
+	joined := strings.Join(lines, "\n")
+	if (joined[0] == '{' || joined[0] == '[') && json.Valid([]byte(joined)) {
+		return langJSON
+	}
+
+	scores := map[string]float64{
+		langShell: share(lines, isShellLine),
+		langYAML:  share(lines, func(line string, _ bool) bool { return commentRe.MatchString(line) || yamlLineRe.MatchString(line) }),
+	}
+	if goStrongRe.MatchString(joined) {
+		scores[langGo] = share(lines, func(line string, _ bool) bool { return goLineRe.MatchString(line) })
+	}
+
+	best, bestScore, tie := langText, 0.0, false
+	for _, lang := range []string{langShell, langYAML, langGo} {
+		switch s := scores[lang]; {
+		case s > bestScore:
+			best, bestScore, tie = lang, s, false
+		case s == bestScore && s > 0:
+			tie = true
+		}
+	}
+	if tie || bestScore < minLanguageConfidence {
+		return langText
+	}
+	if best == langShell && cmdletRe.MatchString(joined) {
+		return langPowerShell
+	}
+	return best
+}
+
+// share returns the fraction of lines that match. match is also told whether
+// the previous line continued onto this one.
+func share(lines []string, match func(line string, continued bool) bool) float64 {
+	n := 0
+	continued := false
+	for _, line := range lines {
+		if match(line, continued) {
+			n++
+		}
+		continued = strings.HasSuffix(line, `\`)
+	}
+	return float64(n) / float64(len(lines))
+}
+
+// isShellLine reports whether a line reads as a shell command, a comment or
+// the continuation of a command.
+func isShellLine(line string, continued bool) bool {
+	if continued || commentRe.MatchString(line) {
+		return true
+	}
+	if flagLineRe.MatchString(line) {
+		return false
+	}
+	line = strings.TrimPrefix(line, "$ ")
+	line = envPrefixRe.ReplaceAllString(line, "")
+	word, _, _ := strings.Cut(line, " ")
+	return shellCommands[word] || strings.HasSuffix(word, "ctl") && word != "ctl" ||
+		strings.HasPrefix(word, "./")
+}
+
+// labelFences adds a detected language hint to the fenced code blocks of lines
+// that have none.
+func labelFences(lines []string) []string {
+	out := make([]string, 0, len(lines))
+	for i := 0; i < len(lines); i++ {
+		if !fenceRe.MatchString(lines[i]) {
+			out = append(out, lines[i])
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && !fenceRe.MatchString(lines[end]) {
+			end++
+		}
+		if end == len(lines) {
+			// Unclosed: leave the rest alone.
+			return append(out, lines[i:]...)
+		}
+		open := lines[i]
+		if m := bareFenceRe.FindStringSubmatch(open); m != nil {
+			open = m[1] + m[2] + detectLanguage(lines[i+1:end])
+		}
+		out = append(out, open)
+		out = append(out, lines[i+1:end+1]...)
+		i = end
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	for _, tc := range []struct{ name, in, want string }{
+		{"command", "source <(omnictl completion bash)", langShell},
+		{"continued command", "talosctl gen config demo https://1.2.3.4:6443 \\\n  --output-dir _out", langShell},
+		{"env prefix and comment", "# build the image\nGOOS=linux make image\nsudo ./install.sh", langShell},
+		{"prompt", "$ kubectl get nodes", langShell},
+		{"powershell", "omnictl completion powershell | Out-String | Invoke-Expression", langPowerShell},
+		{"yaml", "machine:\n  install:\n    disk: /dev/sda\n  # extra\n  kernel:\n    modules:\n      - name: nvidia", langYAML},
+		{"yaml documents", "apiVersion: v1alpha1\nkind: VLANConfig\n---\napiVersion: v1alpha1\nkind: LinkConfig", langYAML},
+		{"json", "{\n  \"a\": [1, 2],\n  \"b\": {\"c\": true}\n}", langJSON},
+		{"go", "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}", langGo},
+		{"cobra flags", "  -h, --help   help for apply\n      --context string   The context to be used.", langText},
+		{"table output", "NAME    READY   STATUS\nomni    1/1     Running", langText},
+		{"broken json", "{\"a\": 1,", langText},
+		{"only comments", "# one\n# two", langText},
+		{"mixed below threshold", "talosctl get members\nNODE   ID\n10.5.0.2   cp-1\n10.5.0.3   worker-1", langText},
+		{"path", "/var/mnt/<metadata.name>", langText},
+		{"empty", "", langText},
+	} {
+		if got := detectLanguage(strings.Split(tc.in, "\n")); got != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestLabelFences(t *testing.T) {
+	in := "```\nkubectl get pods\n```\n\n```yaml\n- a\n```\n\n  ```\n  key: value\n  ```\n\n```\nunclosed\n"
+	want := "```bash\nkubectl get pods\n```\n\n```yaml\n- a\n```\n\n  ```yaml\n  key: value\n  ```\n\n```\nunclosed\n"
+	if got := strings.Join(labelFences(strings.Split(in, "\n")), "\n"); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	// Fences normalize creates are labelled without --label-fences.
+	if got := string(normalizeBytes([]byte("Output:\n\n\tNAME   READY\n"), options{})); got != "Output:\n\n```text\nNAME   READY\n```\n" {
+		t.Errorf("created fence not labelled:\n%s", got)
+	}
+}
@@ -14,16 +14,29 @@
 //     from prose by their intro line: examples are introduced by a line ending
 //     in a colon ("...run:" or a "#### Linux:" heading), so a tab-indented
 //     block with a colon intro is fenced and any other is de-indented.
+//     Fences are labelled with the language detected from their content
+//     (see detectLanguage); with --label-fences, so are existing fences
+//     that have none.
 //
 //   - (with --strip-hr) "---" horizontal-rule separators sprinkled between
 //     sections, which render as noisy horizontal lines.
 //
+// With --cobra, a cobra CLI reference is also restructured per command: a
+// usage fence, the flags as a table or a ParamField list with their type and
+// default, and links to the parent command and subcommands (see
+// restructureCobra). With --headings, headings are re-levelled from H2 and
+// rewritten in sentence case (see normalizeHeadings).
+//
 // The file's leading YAML frontmatter block is always preserved verbatim.
-// The file is normalized in place.
+// Files are normalized in place, atomically, and directories are walked for
+// .md and .mdx files, several files at a time. With --check nothing is
+// written: the files that would change are reported with a diff and the exit
+// code is 1. With --lint the files are only analysed for constructs that
+// would fail to compile as MDX (see analyzeMDX).
 //
 // Usage:
 //
-//	mdx-normalize [--strip-hr] <file.mdx>
+//	mdx-normalize [--strip-hr] [--label-fences] [--cobra table|paramfield] [--headings --heading-exceptions file] [--check | --lint] [--jobs N] [file.mdx | dir ...]
 package main
 
 import (
@@ -32,6 +45,8 @@ import (
 	"io"
 	"os"
 	"regexp"
+	"runtime"
+	"slices"
 	"strings"
 )
 
@@ -40,65 +55,118 @@ var (
 	hrRe    = regexp.MustCompile(`^---[ \t]*$`)
 	colonRe = regexp.MustCompile(`:[ \t]*$`)
 	blankRe = regexp.MustCompile(`^[ \t]*$`)
+	// A line holding only an opening or closing JSX component tag, such as
+	// the <ParamField ...> lines written by --cobra. All-caps names are
+	// placeholders ("<NAME>"), not components.
+	jsxTagLineRe = regexp.MustCompile(`^[ \t]*</?[A-Z][a-z][A-Za-z0-9]*(?:[ \t][^<>]*)?/?>[ \t]*$`)
+	// A line starting with an HTML element, such as the <table> rows
+	// docs-convert writes or a multi-line <iframe>. Placeholders
+	// ("<node-ip>") are not elements.
+	htmlLineRe = regexp.MustCompile(`^[ \t]*</?` + htmlElements + `(?:[ \t/>]|$)`)
+	// Inline markup MDX already parses: an HTML element or component tag, a
+	// string expression such as docs-convert's {"<"}, or {name}.
+	// A tag or template string may go on over the next lines.
+	inlineTagRe  = regexp.MustCompile(`^</?(?:` + htmlElements + `|[A-Z][a-z][A-Za-z0-9]*)(?:(?:[ \t][^<>]*)?/?>|[ \t]*$)`)
+	inlineExprRe = regexp.MustCompile("^\\{\\s*(?:\"[^\"]*\"|`[^`]*`|([A-Za-z_$][\\w$]*))\\s*\\}|^\\{`[^`]*$")
 )
 
+// htmlElements are the HTML elements hand-written and generated pages use.
+const htmlElements = `(?:a|b|br|code|details|div|em|i|iframe|img|li|ol|p|pre|span|strong|sub|summary|sup|table|tbody|td|th|thead|tr|ul|video)`
+
 func main() {
-	stripHR := flag.Bool("strip-hr", false, "remove standalone '---' horizontal-rule separators")
+	opts := options{}
+	flag.BoolVar(&opts.StripHR, "strip-hr", false, "remove standalone '---' horizontal-rule separators")
+	flag.BoolVar(&opts.Check, "check", false, "report the files that would change, with a diff, and exit 1 without writing")
+	flag.BoolVar(&opts.Lint, "lint", false, "report constructs that would fail to compile as MDX, as file:line:col, and exit 1 if any; nothing is written")
+	flag.StringVar(&opts.Cobra, "cobra", "", "restructure a cobra CLI reference per command, rendering flags as a 'table' or a 'paramfield' list")
+	flag.BoolVar(&opts.Label, "label-fences", false, "also add a detected language hint to existing code fences that have none")
+	flag.BoolVar(&opts.Headings, "headings", false, "re-level headings from H2 without gaps and rewrite them in sentence case")
+	exceptions := flag.String("heading-exceptions", "", "file of words that keep their capitalization in headings, such as the style checker's exceptions.txt (required with --headings)")
+	flag.IntVar(&opts.Jobs, "jobs", runtime.GOMAXPROCS(0), "number of files processed in parallel")
 	flag.Usage = func() {
-		fmt.Fprintln(os.Stderr, "usage: mdx-normalize [--strip-hr] [file.mdx]")
-		fmt.Fprintln(os.Stderr, "  With a file argument, the file is normalized in place.")
+		fmt.Fprintln(os.Stderr, "usage: mdx-normalize [--strip-hr] [--label-fences] [--cobra table|paramfield] [--headings --heading-exceptions file] [--check | --lint] [--jobs N] [file.mdx | dir ...]")
+		fmt.Fprintln(os.Stderr, "  Files are normalized in place; directories are walked for .md and .mdx files.")
 		fmt.Fprintln(os.Stderr, "  With no argument (or '-'), reads stdin and writes stdout.")
 		flag.PrintDefaults()
 	}
 	flag.Parse()
-
-	// With a file argument we edit it in place; with none (or "-") we act as a
-	// stdin->stdout filter. The filter mode is used from containers so the file
-	// is never read/written through a bind mount, which avoids Docker Desktop
-	// mount-consistency races on a just-written file.
-	path := ""
-	switch flag.NArg() {
-	case 0:
-		// stdin -> stdout
-	case 1:
-		if flag.Arg(0) != "-" {
-			path = flag.Arg(0)
+	if opts.Cobra != "" && opts.Cobra != cobraTable && opts.Cobra != cobraParamField {
+		fmt.Fprintf(os.Stderr, "mdx-normalize: --cobra must be %q or %q\n", cobraTable, cobraParamField)
+		os.Exit(2)
+	}
+	if opts.Headings && *exceptions == "" {
+		// Without the list, sentence case would lowercase product names.
+		fmt.Fprintln(os.Stderr, "mdx-normalize: --headings needs --heading-exceptions, e.g. the style checker's exceptions.txt")
+		os.Exit(2)
+	}
+	if *exceptions != "" {
+		set, err := loadExceptions(*exceptions)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "mdx-normalize: %v\n", err)
+			os.Exit(2)
 		}
-	default:
-		flag.Usage()
+		opts.Exceptions = set
+	}
+	if opts.Check && opts.Lint {
+		fmt.Fprintln(os.Stderr, "mdx-normalize: --check and --lint are mutually exclusive")
 		os.Exit(2)
 	}
 
-	var (
-		data []byte
-		err  error
-	)
-	if path == "" {
-		data, err = io.ReadAll(os.Stdin)
-	} else {
-		data, err = os.ReadFile(path)
+	// With file arguments we edit them in place; with none (or "-") we act as
+	// a stdin->stdout filter. The filter mode is used from containers so the
+	// file is never read/written through a bind mount, which avoids Docker
+	// Desktop mount-consistency races on a just-written file.
+	if flag.NArg() == 0 || flag.NArg() == 1 && flag.Arg(0) == "-" {
+		os.Exit(filter(os.Stdin, os.Stdout, opts))
 	}
+	if slices.Contains(flag.Args(), "-") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	files, err := collectFiles(flag.Args())
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "mdx-normalize: %v\n", err)
 		os.Exit(1)
 	}
+	os.Exit(report(os.Stdout, os.Stderr, processFiles(files, opts), opts))
+}
+
+// filter normalizes r to w and returns the exit code. In check mode nothing is
+// written but the diff, and the exit code is 1 if the input would change; in
+// lint mode only the MDX problems of the input are reported.
+func filter(r io.Reader, w io.Writer, opts options) int {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mdx-normalize: %v\n", err)
+		return 1
+	}
+	if opts.Lint {
+		return report(w, os.Stderr, []result{{Path: "-", Diags: analyzeMDX(string(data))}}, opts)
+	}
 
-	// Preserve a trailing newline: strings.Split leaves a final "" element
-	// that Join turns back into the closing newline.
-	out := normalize(strings.Split(string(data), "\n"), *stripHR)
-	result := []byte(strings.Join(out, "\n"))
+	out := normalizeBytes(data, opts)
 
-	if path == "" {
-		if _, err = os.Stdout.Write(result); err != nil {
-			fmt.Fprintf(os.Stderr, "mdx-normalize: %v\n", err)
-			os.Exit(1)
+	if opts.Check {
+		if diff := unifiedDiff("a/-", "b/-", data, out); diff != "" {
+			fmt.Fprint(w, diff)
+			return 1
 		}
-		return
+		return 0
 	}
-	if err = os.WriteFile(path, result, 0o644); err != nil {
+	if _, err := w.Write(out); err != nil {
 		fmt.Fprintf(os.Stderr, "mdx-normalize: %v\n", err)
-		os.Exit(1)
+		return 1
 	}
+	return 0
+}
+
+// isExpressionLine reports whether a line starts a JSX expression that is
+// the whole line, or that goes on over the next lines. A "{placeholder}"
+// followed by text is prose.
+func isExpressionLine(line string) bool {
+	t := strings.TrimSpace(line)
+	return strings.HasPrefix(t, "{") && (strings.HasSuffix(t, "}") || braceDepth(t) > 0)
 }
 
 // isFrontmatterDelim reports whether a line is a YAML frontmatter fence,
@@ -113,6 +181,13 @@ func isFrontmatterDelim(line string) bool {
 // the build. Characters inside inline code spans (backtick-delimited) are left
 // alone, and characters already preceded by a backslash are not double-escaped.
 func escapeInlineMDX(s string) string {
+	return escapeProse(s, nil)
+}
+
+// escapeProse is escapeInlineMDX for a line of a page, which may already be
+// MDX: HTML elements, component tags, string expressions and expressions
+// naming a declared (imported or exported) variable are kept as they are.
+func escapeProse(s string, declared map[string]bool) string {
 	if !strings.ContainsAny(s, "<{") {
 		return s
 	}
@@ -127,6 +202,11 @@ func escapeInlineMDX(s string) string {
 			continue
 		}
 		if !inCode && (c == '<' || c == '{') && !(i > 0 && s[i-1] == '\\') {
+			if m := markupAt(s[i:], declared); m != "" {
+				b.WriteString(m)
+				i += len(m) - 1
+				continue
+			}
 			b.WriteByte('\\')
 		}
 		b.WriteByte(c)
@@ -134,6 +214,21 @@ func escapeInlineMDX(s string) string {
 	return b.String()
 }
 
+// markupAt returns the tag or expression s starts with, if MDX parses it as
+// markup the page means, or "".
+func markupAt(s string, declared map[string]bool) string {
+	if declared == nil {
+		return ""
+	}
+	if m := inlineTagRe.FindString(s); m != "" {
+		return m
+	}
+	if m := inlineExprRe.FindStringSubmatch(s); m != nil && (m[1] == "" || declared[m[1]]) {
+		return m[0]
+	}
+	return ""
+}
+
 func normalize(lines []string, stripHR bool) []string {
 	out := make([]string, 0, len(lines))
 	i := 0
@@ -158,6 +253,8 @@ func normalize(lines []string, stripHR bool) []string {
 		lastNonBlank string
 		pendBlanks   int  // blank lines held while a block is open
 		hrSkip       bool // just dropped an HR; swallow one following blank
+		prevBlank    = true
+		declared     = map[string]bool{} // names the page imports or exports
 	)
 
 	flush := func() {
@@ -166,13 +263,13 @@ func normalize(lines []string, stripHR bool) []string {
 		}
 		fenced := colonRe.MatchString(intro)
 		if fenced {
-			out = append(out, "```")
+			out = append(out, "```"+detectLanguage(block))
 			out = append(out, block...)
 			out = append(out, "```")
 		} else {
 			// De-indented prose: escape MDX-significant characters.
 			for _, bl := range block {
-				out = append(out, escapeInlineMDX(bl))
+				out = append(out, escapeProse(bl, declared))
 			}
 		}
 		block = block[:0]
@@ -183,6 +280,8 @@ func normalize(lines []string, stripHR bool) []string {
 
 	for ; i < len(lines); i++ {
 		line := lines[i]
+		wasBlank := prevBlank
+		prevBlank = blankRe.MatchString(line)
 
 		switch {
 		case fenceRe.MatchString(line):
@@ -219,10 +318,47 @@ func normalize(lines []string, stripHR bool) []string {
 				out = append(out, line)
 			}
 
+		case wasBlank && esmStartRe.MatchString(line):
+			// An import/export block is JavaScript up to the next blank line.
+			hrSkip = false
+			flush()
+			start := i
+			for ; i < len(lines) && !blankRe.MatchString(lines[i]); i++ {
+				out = append(out, lines[i])
+			}
+			for _, stmt := range splitStatements(strings.Join(lines[start:i], "\n")) {
+				declareESM(stmt, declared)
+			}
+			i--
+			lastNonBlank = lines[i]
+
+		case isExpressionLine(line):
+			// A JSX expression, such as a {/* ... */} comment, runs until
+			// its braces balance, possibly over several lines.
+			hrSkip = false
+			flush()
+			for start := i; i < len(lines); i++ {
+				out = append(out, lines[i])
+				if braceDepth(strings.Join(lines[start:i+1], "\n")) <= 0 {
+					break
+				}
+			}
+			if i == len(lines) {
+				i--
+			}
+			lastNonBlank = lines[i]
+			prevBlank = false
+
+		case jsxTagLineRe.MatchString(line), anchorLineRe.MatchString(line), htmlLineRe.MatchString(line):
+			hrSkip = false
+			flush()
+			out = append(out, line)
+			lastNonBlank = line
+
 		default:
 			hrSkip = false
 			flush()
-			out = append(out, escapeInlineMDX(line))
+			out = append(out, escapeProse(line, declared))
 			lastNonBlank = line
 		}
 	}
@@ -12,7 +12,7 @@ func run(t *testing.T, in string, stripHR bool) string {
 
 func TestColonIntroBecomesFencedCode(t *testing.T) {
 	in := "To load completions in your current shell session:\n\n\tsource <(omnictl completion bash)\n\nnext paragraph\n"
-	want := "To load completions in your current shell session:\n\n```\nsource <(omnictl completion bash)\n```\n\nnext paragraph\n"
+	want := "To load completions in your current shell session:\n\n```bash\nsource <(omnictl completion bash)\n```\n\nnext paragraph\n"
 	if got := run(t, in, false); got != want {
 		t.Errorf("colon intro not fenced:\n got: %q\nwant: %q", got, want)
 	}
@@ -22,7 +22,7 @@ func TestHeadingColonIntroBecomesFencedCode(t *testing.T) {
 	// A command example without any MDX-breaking characters must still be
 	// fenced when introduced by a colon (here a "#### Linux:" heading).
 	in := "#### Linux:\n\n\tomnictl completion bash > /etc/bash_completion.d/omnictl\n"
-	want := "#### Linux:\n\n```\nomnictl completion bash > /etc/bash_completion.d/omnictl\n```\n"
+	want := "#### Linux:\n\n```bash\nomnictl completion bash > /etc/bash_completion.d/omnictl\n```\n"
 	if got := run(t, in, false); got != want {
 		t.Errorf("heading-colon intro not fenced:\n got: %q\nwant: %q", got, want)
 	}
@@ -106,3 +106,60 @@ func TestStripHRKeepsFencedSeparators(t *testing.T) {
 		t.Errorf("fenced --- was stripped:\n got: %q\nwant: %q", got, in)
 	}
 }
+
+func TestComponentTagLinesKept(t *testing.T) {
+	// Lines holding a single component tag are JSX, while all-caps
+	// placeholders are still escaped.
+	in := "<ParamField path=\"--file\" type=\"string\">\n  The <path> to load.\n</ParamField>\n<NAME>\n"
+	want := "<ParamField path=\"--file\" type=\"string\">\n  The \\<path> to load.\n</ParamField>\n\\<NAME>\n"
+	if got := run(t, in, false); got != want {
+		t.Errorf("component tags not kept:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestCommentLinesKept(t *testing.T) {
+	// A style-guide-checker suppression comment is MDX, not text to escape.
+	in := "{/* style-ignore-next-line code/sed */}\n\nText {x}.\n"
+	want := "{/* style-ignore-next-line code/sed */}\n\nText \\{x}.\n"
+	if got := run(t, in, false); got != want {
+		t.Errorf("comment line not kept:\n got: %q\nwant: %q", got, want)
+	}
+}
+
+func TestHandWrittenPageUnchanged(t *testing.T) {
+	// A page that is already MDX: imports, expressions, components and
+	// HTML are kept, so --check passes on the hand-written docs.
+	in := `---
+title: Quickstart
+---
+
+import { VersionWarningBanner } from "/snippets/version-warning-banner.jsx"
+import { release_v1_14 } from '/snippets/custom-variables.mdx';
+
+<VersionWarningBanner />
+
+{/*
+  Keep in sync with the installation guide.
+*/}
+
+Download Talos **{release_v1_14}**, see <a href={` + "`https://factory.talos.dev/?version=${release_v1_14}`" + `}>the factory</a>.
+
+<table>
+    <tr>
+      <td>The {"<"}node-ip{">"} of the node.</td>
+    </tr>
+</table>
+`
+	if got := run(t, in, false); got != in {
+		t.Errorf("hand-written page changed:\n got: %q\nwant: %q", got, in)
+	}
+}
+
+func TestUndeclaredExpressionEscaped(t *testing.T) {
+	// Only names the page imports are expressions; others are placeholders.
+	in := "import { a } from './a.mdx'\n\nUse {a} and {b}.\n"
+	want := "import { a } from './a.mdx'\n\nUse {a} and \\{b}.\n"
+	if got := run(t, in, false); got != want {
+		t.Errorf("expressions not told apart:\n got: %q\nwant: %q", got, want)
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const baselineHeader = `# style-guide-checker baseline: findings that do not fail the run.
+# One line per finding: file, rule and a hash of the flagged line's content.
+# Written by -write-baseline; -baseline removes the findings that are fixed.
+`
+
+// baselineEntry identifies a finding independently of its line number, so
+// edits elsewhere in the page do not turn a known finding into a new one.
+type baselineEntry struct {
+	File string // relative to the baseline file, with forward slashes
+	Rule string
+	Hash string // of the flagged line, whitespace-normalized
+}
+
+// A baseline is a multiset of accepted findings. Findings are matched against
+// it file by file; entries of files that were checked but no longer match are
+// dropped when it is saved, so it only ever shrinks.
+type baseline struct {
+	path    string
+	dir     string
+	entries map[baselineEntry]int
+	kept    map[baselineEntry]int // the matched entries of checked files
+	checked map[string]bool
+}
+
+// loadBaseline reads a baseline file. A missing file is an empty baseline.
+func loadBaseline(p string) (*baseline, error) {
+	dir, err := filepath.Abs(filepath.Dir(p))
+	if err != nil {
+		return nil, err
+	}
+	b := &baseline{
+		path:    p,
+		dir:     dir,
+		entries: map[baselineEntry]int{},
+		kept:    map[baselineEntry]int{},
+		checked: map[string]bool{},
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return b, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := strings.Split(line, "\t")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("%s:%d: want file, rule and hash separated by tabs", p, n)
+		}
+		b.entries[baselineEntry{parts[0], parts[1], parts[2]}]++
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// filter returns the findings of one file that are not in the baseline and
+// the number that are. content is the file the findings were made on.
+func (b *baseline) filter(file, content string, findings []Finding) ([]Finding, int) {
+	rel := relTo(b.dir, file)
+	b.checked[rel] = true
+	lines := strings.Split(content, "\n")
+	left := map[baselineEntry]int{}
+	out := findings[:0]
+	known := 0
+	for _, f := range findings {
+		e := baselineEntry{rel, f.Rule, lineHash(lines, f.Line)}
+		if _, ok := left[e]; !ok {
+			left[e] = b.entries[e]
+		}
+		if left[e] == 0 {
+			out = append(out, f)
+			continue
+		}
+		left[e]--
+		b.kept[e]++
+		known++
+	}
+	return out, known
+}
+
+// record adds the findings of one file, replacing what the baseline held for
+// it.
+func (b *baseline) record(file, content string, findings []Finding) {
+	rel := relTo(b.dir, file)
+	b.checked[rel] = true
+	lines := strings.Split(content, "\n")
+	for _, f := range findings {
+		b.kept[baselineEntry{rel, f.Rule, lineHash(lines, f.Line)}]++
+	}
+}
+
+// result returns the baseline to save: the kept entries of the checked files
+// and every entry of the files that were not checked.
+func (b *baseline) result() map[baselineEntry]int {
+	res := map[baselineEntry]int{}
+	for e, n := range b.entries {
+		if !b.checked[e.File] {
+			res[e] = n
+		}
+	}
+	for e, n := range b.kept {
+		res[e] = n
+	}
+	return res
+}
+
+// baselineSize returns the number of findings in a baseline.
+func baselineSize(entries map[baselineEntry]int) int {
+	n := 0
+	for _, c := range entries {
+		n += c
+	}
+	return n
+}
+
+// save writes entries to the baseline file, sorted so that diffs stay small.
+func (b *baseline) save(entries map[baselineEntry]int) error {
+	var lines []string
+	for e, n := range entries {
+		for range n {
+			lines = append(lines, e.File+"\t"+e.Rule+"\t"+e.Hash)
+		}
+	}
+	sort.Strings(lines)
+	var sb strings.Builder
+	sb.WriteString(baselineHeader)
+	for _, l := range lines {
+		sb.WriteString(l)
+		sb.WriteByte('\n')
+	}
+	return writeFileAtomic(b.path, []byte(sb.String()))
+}
+
+// lineHash returns a short hash of a 1-based line with its whitespace
+// collapsed, so re-indenting a line keeps its findings known.
+func lineHash(lines []string, line int) string {
+	text := ""
+	if line >= 1 && line <= len(lines) {
+		text = strings.Join(strings.Fields(lines[line-1]), " ")
+	}
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const baselineDoc = "---\ntitle: Test\n---\n\n## Getting Started\n\nSee [here](/x).\n"
+
+func TestBaselineRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "baseline")
+	page := filepath.Join(dir, "docs", "a.mdx")
+
+	b, err := loadBaseline(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.record(page, baselineDoc, lint(page, baselineDoc))
+	if err := b.save(b.result()); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "docs/a.mdx\theadings/sentence-case\t") {
+		t.Fatalf("baseline does not use paths relative to itself:\n%s", data)
+	}
+
+	// Lines shift and one finding is new: only that one is reported.
+	shifted := strings.Replace(baselineDoc, "## Getting", "Intro.\n\n## Getting", 1) + "\n## Next Steps\n\nText.\n"
+	b, err = loadBaseline(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, known := b.filter(page, shifted, lint(page, shifted))
+	if known != 2 || len(got) != 1 || got[0].Line != 11 {
+		t.Errorf("got %d known and %+v", known, got)
+	}
+}
+
+func TestBaselineShrinks(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "baseline")
+	a, other := filepath.Join(dir, "a.mdx"), filepath.Join(dir, "b.mdx")
+
+	b, _ := loadBaseline(p)
+	b.record(a, baselineDoc, lint(a, baselineDoc))
+	b.record(other, baselineDoc, lint(other, baselineDoc))
+	if err := b.save(b.result()); err != nil {
+		t.Fatal(err)
+	}
+
+	// a.mdx gets its heading fixed; b.mdx is not checked this time.
+	fixed := strings.Replace(baselineDoc, "Getting Started", "Getting started", 1)
+	b, _ = loadBaseline(p)
+	if got, _ := b.filter(a, fixed, lint(a, fixed)); len(got) != 0 {
+		t.Errorf("new findings: %+v", got)
+	}
+	after := b.result()
+	if baselineSize(b.entries) != 4 || baselineSize(after) != 3 {
+		t.Errorf("baseline went from %d to %d findings, want 4 to 3", baselineSize(b.entries), baselineSize(after))
+	}
+	if after[baselineEntry{"b.mdx", "headings/sentence-case", lineHash([]string{"## Getting Started"}, 1)}] != 1 {
+		t.Errorf("entries of the unchecked file dropped: %v", after)
+	}
+}
+
+func TestBaselineCountsDuplicates(t *testing.T) {
+	b, _ := loadBaseline(filepath.Join(t.TempDir(), "baseline"))
+	doc := "See [here](/x).\n\nSee [here](/x).\n"
+	b.record("a.mdx", doc, lint("a.mdx", doc[:16]))
+	more := doc + "\nSee [here](/x).\n"
+	b.entries, b.kept = b.kept, map[baselineEntry]int{}
+	if got, known := b.filter("a.mdx", more, lint("a.mdx", more)); known != 1 || len(got) != 2 {
+		t.Errorf("got %d known and %+v", known, got)
+	}
+}
+
+func TestLineHashIgnoresWhitespace(t *testing.T) {
+	if lineHash([]string{"  $ ls   -la"}, 1) != lineHash([]string{"$ ls -la"}, 1) {
+		t.Error("indentation changes the hash")
+	}
+	if lineHash([]string{"$ ls"}, 1) == lineHash([]string{"$ ls -la"}, 1) {
+		t.Error("content does not change the hash")
+	}
+}
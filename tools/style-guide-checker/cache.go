@@ -0,0 +1,180 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// A cache holds the findings of files checked before, one entry per file in
+// the user cache directory. An entry is reused while its key matches: the
+// hash of the file content, of the checker binary (which embeds the rules,
+// exceptions and vocabulary), of the configuration and, for a page of a site,
+// of the site the links and versions of the page are checked against. The
+// anchors of the pages the file links to are part of the entry too, so only
+// those pages are read to validate it.
+type cache struct {
+	dir     string
+	version string // hash of the checker binary
+	config  string // Config.fingerprint
+}
+
+type cacheEntry struct {
+	Key      string            `json:"key"`
+	Anchors  map[string]string `json:"anchors,omitempty"` // linked page -> anchorsHash
+	Findings []Finding         `json:"findings"`
+}
+
+// openCache returns the cache of the user cache directory, or nil if there is
+// none (as in the container) or the checker binary cannot be read.
+func openCache(cfg *Config) *cache {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return nil
+	}
+	version, err := executableHash()
+	if err != nil {
+		return nil
+	}
+	dir := filepath.Join(base, "style-guide-checker")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil
+	}
+	return &cache{dir: dir, version: version, config: cfg.fingerprint()}
+}
+
+// executableHash hashes the running binary. Go builds are reproducible, so
+// it changes exactly when the checker does, with go run as well.
+func executableHash() (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	f, err := os.Open(exe)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// key returns the cache key of a file's content, and the path of its entry.
+func (c *cache) key(file, content string) (key, entry string, err error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", "", err
+	}
+	siteHash := ""
+	if s := siteOf(file); s != nil {
+		siteHash = s.fingerprint()
+	}
+	h := sha256.New()
+	for _, part := range []string{c.version, c.config, siteHash, abs, content} {
+		fmt.Fprintf(h, "%d:%s", len(part), part)
+	}
+	name := sha256.Sum256([]byte(abs))
+	return hex.EncodeToString(h.Sum(nil)), filepath.Join(c.dir, hex.EncodeToString(name[:16])+".json"), nil
+}
+
+// get returns the cached findings of a file, with their File set to file.
+func (c *cache) get(file, content string) ([]Finding, bool) {
+	key, entry, err := c.key(file, content)
+	if err != nil {
+		return nil, false
+	}
+	data, err := os.ReadFile(entry)
+	if err != nil {
+		return nil, false
+	}
+	var e cacheEntry
+	if json.Unmarshal(data, &e) != nil || e.Key != key {
+		return nil, false
+	}
+	if len(e.Anchors) > 0 {
+		s := siteOf(file)
+		if s == nil {
+			return nil, false
+		}
+		for page, hash := range e.Anchors {
+			if s.anchorsHash(page) != hash {
+				return nil, false
+			}
+		}
+	}
+	for i := range e.Findings {
+		e.Findings[i].File = file
+	}
+	return e.Findings, true
+}
+
+// put records the findings of a file, which links to the anchors of the
+// linked pages. A cache that cannot be written only costs time, so errors
+// are ignored.
+func (c *cache) put(file, content string, findings []Finding, linked []string) {
+	key, entry, err := c.key(file, content)
+	if err != nil {
+		return
+	}
+	e := cacheEntry{Key: key, Findings: findings}
+	if s := siteOf(file); s != nil && len(linked) > 0 {
+		e.Anchors = map[string]string{}
+		for _, page := range linked {
+			e.Anchors[page] = s.anchorsHash(page)
+		}
+	}
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	_ = writeFileAtomic(entry, data)
+}
+
+// fingerprint hashes what the links and versions of every page of the site
+// are checked against: docs.json, the version variables and the list of
+// pages. Editing a page leaves the cached findings of the others valid; adding
+// or removing a page does not. The anchors of a page only matter to the pages
+// linking to it (see anchorsHash).
+func (s *site) fingerprint() string {
+	s.fingerprintOnce.Do(func() {
+		h := sha256.New()
+		if data, err := os.ReadFile(filepath.Join(s.root, siteConfigName)); err == nil {
+			h.Write(data)
+		}
+		for _, v := range s.variables {
+			fmt.Fprintf(h, "\x00%s=%s", v.name, v.value)
+		}
+		pages := make([]string, 0, len(s.pages))
+		for p := range s.pages {
+			pages = append(pages, p)
+		}
+		sort.Strings(pages)
+		for _, p := range pages {
+			fmt.Fprintf(h, "\x00%s", p)
+		}
+		s.fingerprintHash = hex.EncodeToString(h.Sum(nil))
+	})
+	return s.fingerprintHash
+}
+
+// anchorsHash hashes the anchors of a page.
+func (s *site) anchorsHash(page string) string {
+	anchors := make([]string, 0)
+	for a := range s.pageAnchors(page) {
+		anchors = append(anchors, a)
+	}
+	sort.Strings(anchors)
+	h := sha256.New()
+	for _, a := range anchors {
+		fmt.Fprintf(h, "\x00%s", a)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCacheRoundTrip(t *testing.T) {
+	c := &cache{dir: t.TempDir(), version: "v1", config: "c1"}
+	file := filepath.Join(t.TempDir(), "a.mdx")
+	content := "# Title\n"
+	findings := lint(file, content)
+	if len(findings) == 0 {
+		t.Fatal("no findings to cache")
+	}
+
+	if _, ok := c.get(file, content); ok {
+		t.Fatal("hit in an empty cache")
+	}
+	c.put(file, content, findings, nil)
+	got, ok := c.get(file, content)
+	if !ok || !reflect.DeepEqual(got, findings) {
+		t.Fatalf("got %+v, %v; want %+v", got, ok, findings)
+	}
+
+	for name, other := range map[string]*cache{
+		"content": c,
+		"version": {dir: c.dir, version: "v2", config: "c1"},
+		"config":  {dir: c.dir, version: "v1", config: "c2"},
+	} {
+		changed := content
+		if name == "content" {
+			changed += "\nMore.\n"
+		}
+		if _, ok := other.get(file, changed); ok {
+			t.Errorf("hit after the %s changed", name)
+		}
+	}
+}
+
+func TestCacheKeepsFileName(t *testing.T) {
+	c := &cache{dir: t.TempDir(), version: "v1"}
+	dir := t.TempDir()
+	abs := filepath.Join(dir, "a.mdx")
+	c.put(abs, "# Title\n", lint(abs, "# Title\n"), nil)
+
+	// The same file named relative to another working directory.
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rel, err := filepath.Rel(wd, abs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := c.get(rel, "# Title\n")
+	if !ok || len(got) == 0 || got[0].File != rel {
+		t.Errorf("got %+v, %v", got, ok)
+	}
+}
+
+func TestSiteFingerprint(t *testing.T) {
+	pages := map[string]string{"talos/v1.1/a": "## Install\n"}
+	fingerprint := func(pages map[string]string) string {
+		root := writeSite(t, pages)
+		return siteOf(filepath.Join(root, "talos", "v1.1", "a.mdx")).fingerprint()
+	}
+	base := fingerprint(pages)
+	// Anchors are checked per linked page, not through the fingerprint.
+	for name, changed := range map[string]map[string]string{
+		"prose":   {"talos/v1.1/a": "## Install\n\nMore prose.\n"},
+		"heading": {"talos/v1.1/a": "## Upgrade\n"},
+	} {
+		if fingerprint(changed) != base {
+			t.Errorf("fingerprint changed with the %s of a page", name)
+		}
+	}
+	if fingerprint(map[string]string{"talos/v1.1/a": "## Install\n", "talos/v1.1/b": ""}) == base {
+		t.Error("fingerprint unchanged after a page was added")
+	}
+}
+
+func TestCacheChecksLinkedAnchors(t *testing.T) {
+	doc := "See [install](/talos/v1.1/b#install) and [c](/talos/v1.1/guides/c).\n"
+	root := writeSite(t, map[string]string{
+		"talos/v1.1/a":        doc,
+		"talos/v1.1/b":        "## Install\n",
+		"talos/v1.1/guides/c": "## Usage\n",
+	})
+	file := filepath.Join(root, "talos", "v1.1", "a.mdx")
+	c := &cache{dir: t.TempDir(), version: "v1"}
+	check := func(want bool) {
+		t.Helper()
+		// A new site index, as in a new run.
+		sites.Lock()
+		clear(sites.byDir)
+		sites.Unlock()
+		if r := lintFile(file, nil, c, false); r.err != nil || r.cached != want {
+			t.Errorf("got %+v, want cached %v", r, want)
+		}
+	}
+	check(false)
+	check(true)
+
+	// The headings of a page the file does not link to an anchor of do not
+	// matter; those of the linked page do.
+	for page, content := range map[string]string{"guides/c": "## Other\n", "b": "## Upgrade\n"} {
+		if err := os.WriteFile(filepath.Join(root, "talos", "v1.1", page+".mdx"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		check(page == "guides/c")
+	}
+}
+
+func TestLintFilesKeepsOrder(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for _, name := range []string{"c.mdx", "a.mdx", "b.mdx"} {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte("# "+name+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, p)
+	}
+	files = append(files, filepath.Join(dir, "missing.mdx"))
+
+	c := &cache{dir: t.TempDir(), version: "v1"}
+	for run := range 2 {
+		results := lintFiles(files, nil, c, false)
+		for i, r := range results[:3] {
+			if r.err != nil || len(r.findings) == 0 || r.findings[0].File != files[i] || r.cached != (run == 1) {
+				t.Errorf("run %d, %s: got %+v", run, files[i], r)
+			}
+		}
+		if r := results[3]; r.err == nil || !strings.Contains(r.err.Error(), "missing.mdx") {
+			t.Errorf("run %d: got error %v for a missing file", run, r.err)
+		}
+	}
+}
+
+func TestSortFindings(t *testing.T) {
+	findings := []Finding{
+		{"b.mdx", 1, Warning, "x", "", 0, 0},
+		{"a.mdx", 2, Warning, "z", "", 5, 6},
+		{"a.mdx", 2, Warning, "y", "", 5, 6},
+		{"a.mdx", 2, Warning, "w", "", 1, 2},
+		{"a.mdx", 1, Warning, "v", "", 9, 9},
+	}
+	sortFindings(findings)
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.Rule)
+	}
+	if strings.Join(got, "") != "vwyzx" {
+		t.Errorf("got order %v", got)
+	}
+}
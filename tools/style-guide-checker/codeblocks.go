@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// A codeBlock is the content of a fenced code block, with the indentation of
+// its fence removed from every line.
+type codeBlock struct {
+	lang  string
+	start int // 1-based line of the first content line
+	lines []string
+	// indent is what was removed from each line, so columns can be mapped back.
+	indent []int
+}
+
+// newCodeBlock starts a block whose fence line is fence.
+func newCodeBlock(fence, lang string, start int) *codeBlock {
+	return &codeBlock{lang: lang, start: start, indent: []int{len(fence) - len(strings.TrimLeft(fence, " \t"))}}
+}
+
+// append adds a content line, removing up to the indentation of the fence.
+func (b *codeBlock) append(line string) {
+	n := 0
+	for n < b.indent[0] && n < len(line) && (line[n] == ' ' || line[n] == '\t') {
+		n++
+	}
+	b.lines = append(b.lines, line[n:])
+	b.indent = append(b.indent, n)
+}
+
+// at returns the file line and column of a 1-based line and column of the
+// block content, clamped to the block.
+func (b *codeBlock) at(line, col int) (int, int) {
+	line = max(1, min(line, len(b.lines)))
+	if col > 0 {
+		col += b.indent[line]
+	}
+	return b.start + line - 1, col
+}
+
+var (
+	// yamlErrLineRe matches the line of a yaml.v3 error.
+	yamlErrLineRe = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+	// placeholderRe matches a placeholder such as <node-ip>, <email address>
+	// or the <uuid> of talos<uuid>, which the shell would read as
+	// redirections. The "<" of a here-document is not one.
+	placeholderRe = regexp.MustCompile(`(?:^|[^<])<[A-Za-z][^<>]*>`)
+	// heredocRe matches a shell here-document, such as cat <<EOF > patch.yaml.
+	heredocRe = regexp.MustCompile(`<<-?\s*['"]?\w+`)
+)
+
+// isElision reports whether a line only stands for left-out lines, such as
+// "..." inside a configuration excerpt.
+func isElision(line string) bool {
+	t := strings.TrimSpace(line)
+	return t == "..." || t == "…"
+}
+
+// checkCodeBlock applies the validator of the block's language, if it has one.
+func checkCodeBlock(add func(line, col, endCol int, level Level, rule, msg string), b *codeBlock) {
+	if len(b.lines) == 0 {
+		return
+	}
+	switch lang := strings.ToLower(b.lang); {
+	case lang == "yaml" || lang == "yml":
+		checkYAMLBlock(add, b)
+	case lang == "json":
+		checkJSONBlock(add, b)
+	case syntaxShellLangs[lang]:
+		checkShellBlock(add, b)
+	}
+	if shellLangs[b.lang] {
+		checkContinuations(add, b)
+	}
+}
+
+// syntaxShellLangs are the languages checked as shell scripts. Un-hinted and
+// session blocks are left out; they mix commands with their output.
+var syntaxShellLangs = map[string]bool{"bash": true, "sh": true, "shell": true, "zsh": true}
+
+// checkYAMLBlock reports the first error of the YAML documents of a block.
+// Elided lines ("...") are read as blank lines.
+func checkYAMLBlock(add func(line, col, endCol int, level Level, rule, msg string), b *codeBlock) {
+	if heredocRe.MatchString(b.lines[0]) {
+		l, _ := b.at(1, 0)
+		add(l, 0, 0, ErrorLevel, "code/yaml-syntax", "the block is a shell command writing YAML; tag it ```bash")
+		return
+	}
+	lines := make([]string, len(b.lines))
+	for i, line := range b.lines {
+		if !isElision(line) {
+			lines[i] = line
+		}
+	}
+	dec := yaml.NewDecoder(strings.NewReader(strings.Join(lines, "\n")))
+	for {
+		var n yaml.Node
+		err := dec.Decode(&n)
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			line, msg := 1, strings.TrimPrefix(err.Error(), "yaml: ")
+			if m := yamlErrLineRe.FindStringSubmatch(err.Error()); m != nil {
+				line, _ = strconv.Atoi(m[1])
+				msg = m[2]
+			}
+			l, _ := b.at(line, 0)
+			add(l, 0, 0, ErrorLevel, "code/yaml-syntax", "YAML does not parse: "+msg)
+			// The decoder cannot go on after an error.
+			return
+		}
+	}
+}
+
+// checkJSONBlock reports the first error of a block of JSON values. A block
+// with elided lines ("...") is an excerpt and is not checked.
+func checkJSONBlock(add func(line, col, endCol int, level Level, rule, msg string), b *codeBlock) {
+	if slices.ContainsFunc(b.lines, isElision) {
+		return
+	}
+	src := strings.Join(b.lines, "\n")
+	dec := json.NewDecoder(strings.NewReader(src))
+	for {
+		var v any
+		err := dec.Decode(&v)
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err == nil {
+			continue
+		}
+		offset := len(src)
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) {
+			offset = int(syntaxErr.Offset)
+		}
+		// The offset is just past the offending byte.
+		before := src[:max(0, offset-1)]
+		line := strings.Count(before, "\n") + 1
+		col := len(before) - strings.LastIndexByte(before, '\n')
+		l, c := b.at(line, col)
+		add(l, c, c+1, ErrorLevel, "code/json-syntax", "JSON does not parse: "+strings.TrimPrefix(err.Error(), "json: "))
+		return
+	}
+}
+
+// checkShellBlock reports the first syntax error of a shell block, such as an
+// unterminated quote or a pipe with no command after it. Placeholders like
+// <node-ip> are read as words. A block of "$ " prompts is parsed without them;
+// a session that also shows output is not a script and is not checked.
+func checkShellBlock(add func(line, col, endCol int, level Level, rule, msg string), b *codeBlock) {
+	prompted, only := promptSession(b.lines)
+	if prompted && !only {
+		return
+	}
+	var src bytes.Buffer
+	for _, line := range b.lines {
+		if prompted {
+			// Blank the prompt, keeping the columns.
+			line = promptPrefixRe.ReplaceAllStringFunc(line, func(p string) string {
+				return strings.Repeat(" ", len(p))
+			})
+		}
+		src.WriteString(placeholderRe.ReplaceAllStringFunc(line, func(s string) string {
+			i := strings.IndexByte(s, '<')
+			return s[:i] + strings.NewReplacer("<", "_", ">", "_", " ", "_").Replace(s[i:])
+		}))
+		src.WriteByte('\n')
+	}
+	_, err := syntax.NewParser(syntax.Variant(syntax.LangBash)).Parse(&src, "")
+	var parseErr syntax.ParseError
+	if !errors.As(err, &parseErr) {
+		return
+	}
+	l, c := b.at(int(parseErr.Pos.Line()), int(parseErr.Pos.Col()))
+	add(l, c, c+1, Warning, "code/shell-syntax", "shell does not parse: "+parseErr.Text)
+}
+
+// promptSession reports whether lines of a code block have "$ " prompts, and
+// whether every non-blank line is a prompt or the continuation of one, so
+// that the block holds commands only.
+func promptSession(lines []string) (prompted, only bool) {
+	only, continued := true, false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		switch {
+		case promptRe.MatchString(line):
+			prompted = true
+		case !continued:
+			only = false
+		}
+		continued = strings.HasSuffix(trimmed, `\`)
+	}
+	return prompted, only
+}
+
+// checkContinuations flags a line continuation ("\" at the end of a line)
+// followed by a blank line or the end of the block: pasted, the command ends
+// there and the rest runs as a command of its own, or not at all.
+func checkContinuations(add func(line, col, endCol int, level Level, rule, msg string), b *codeBlock) {
+	for i, line := range b.lines {
+		trimmed := strings.TrimRight(line, " \t")
+		if !strings.HasSuffix(trimmed, `\`) || strings.HasSuffix(trimmed, `\\`) {
+			continue
+		}
+		var msg string
+		switch {
+		case i+1 == len(b.lines):
+			msg = "line continuation at the end of the block; remove the \"\\\" or add the rest of the command"
+		case strings.TrimSpace(b.lines[i+1]) == "":
+			msg = "line continuation followed by a blank line; remove the blank line or the \"\\\""
+		default:
+			continue
+		}
+		l, c := b.at(i+1, len(trimmed))
+		add(l, c, c+1, ErrorLevel, "code/continuation", msg)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// codeFindings returns the findings of the code block validators as
+// "line rule" strings.
+func codeFindings(doc string) []string {
+	var got []string
+	for _, f := range lint("t.mdx", doc) {
+		switch f.Rule {
+		case "code/yaml-syntax", "code/json-syntax", "code/shell-syntax", "code/continuation":
+			got = append(got, fmt.Sprintf("%d %s", f.Line, f.Rule))
+		}
+	}
+	return got
+}
+
+func TestCodeBlockValidators(t *testing.T) {
+	for _, tc := range []struct {
+		name, doc string
+		want      []string
+	}{
+		{"valid yaml", "```yaml\na: 1\nb:\n  - c\n```\n", nil},
+		{"invalid yaml", "Text.\n\n```yaml\na: 1\nb: c: d\n```\n", []string{"5 code/yaml-syntax"}},
+		{"second yaml document", "```yaml\na: 1\n---\nb: c: d\n---\nc: 3\n```\n", []string{"4 code/yaml-syntax"}},
+		{"elided yaml", "```yaml\nmachine:\n  ...\n  network: {}\n```\n", nil},
+		{"heredoc tagged yaml", "```yaml\ncat <<EOF > patch.yaml\na: 1\nEOF\n```\n", []string{"2 code/yaml-syntax"}},
+		{"valid json", "```json\n{\"a\": [1, 2]}\n{\"b\": 3}\n```\n", nil},
+		{"invalid json", "```json\n{\n  \"a\": 1,\n  \"b\" 2\n}\n```\n", []string{"4 code/json-syntax"}},
+		{"elided json", "```json\n{\n  ...\n}\n```\n", nil},
+		{"valid shell", "```bash\ntalosctl -n <node ip> get members | grep foo\ncat <<EOF > a.yaml\nx: \"y\n'\nEOF\n```\n", nil},
+		{"unterminated quote", "```bash\necho ok\necho \"oops\n```\n", []string{"3 code/shell-syntax"}},
+		{"pipe across lines", "```sh\necho a |\n\n  grep a\n```\n", nil},
+		{"dangling pipe at end", "```sh\necho a\necho b |\n```\n", []string{"3 code/shell-syntax"}},
+		{"blank after continuation", "```bash\ntalosctl gen config \\\n\n  --force\n```\n", []string{"2 code/continuation"}},
+		{"continuation at end", "```\ntalosctl gen config \\\n```\n", []string{"2 code/continuation"}},
+		{"indented block", "<Step>\n  ```json\n  {\"a\": }\n  ```\n</Step>\n", []string{"3 code/json-syntax"}},
+		{"other language", "```text\n\"unterminated\n```\n", nil},
+		{"placeholder after a word", "```bash\ntalosctl get disks talos<uuid>\n```\n", nil},
+		{"session with output", "```bash\n$ for i in a b; do\n  ok\n$ talosctl get members\nNODE   ID[1]\n```\n", nil},
+		{"prompted commands", "```bash\n$ talosctl get members \\\n    --nodes 10.5.0.2\n$ echo \"oops\n```\n", []string{"4 code/shell-syntax"}},
+	} {
+		got := codeFindings(tc.doc)
+		if strings.Join(got, "\n") != strings.Join(tc.want, "\n") {
+			t.Errorf("%s: got %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestCodeBlockColumns(t *testing.T) {
+	doc := "<Step>\n  ```json\n  {\"a\": }\n  ```\n</Step>\n"
+	for _, f := range lint("t.mdx", doc) {
+		if f.Rule == "code/json-syntax" && (f.Line != 3 || f.Column != 9) {
+			t.Errorf("got line %d column %d, want line 3 column 9", f.Line, f.Column)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configFileName is the rule configuration looked up from the working
+// directory upwards.
+const configFileName = ".styleguide.yaml"
+
+// Severities of the configuration. "off" disables a rule.
+const (
+	severityOff     = "off"
+	severityWarning = "warning"
+	severityError   = "error"
+)
+
+// Config is the rule configuration of a .styleguide.yaml file:
+//
+//	rules:
+//	  code/sed: off
+//	  headings/*: error
+//	overrides:
+//	  - paths: ["**/reference/**"]
+//	    rules:
+//	      headings/*: off
+//	options:
+//	  badLinkText: [here, click here]
+//	  shellLangs: ["", bash, sh]
+//	  vocabulary: docs-vocabulary.yaml
+//
+// Rule keys are rule IDs or path.Match patterns of rule IDs; an exact ID wins
+// over a pattern. Overrides apply in order to the files matching one of their
+// paths, relative to the directory of the configuration file, where "**"
+// matches any number of directories.
+type Config struct {
+	Rules     map[string]string `yaml:"rules"`
+	Overrides []Override        `yaml:"overrides"`
+	Options   RuleOptions       `yaml:"options"`
+
+	dir   string     // directory the paths of overrides are relative to
+	terms []termRule // the rules of Options.Vocabulary, if set
+	hash  string     // of the file, its directory and the vocabulary
+}
+
+// Override sets the severity of rules for some paths.
+type Override struct {
+	Paths []string          `yaml:"paths"`
+	Rules map[string]string `yaml:"rules"`
+}
+
+// RuleOptions replace the built-in settings of individual rules.
+type RuleOptions struct {
+	// BadLinkText is the link text links/non-descriptive reports.
+	BadLinkText []string `yaml:"badLinkText"`
+	// ShellLangs are the code block languages the shell rules check; "" is a
+	// block without a language hint.
+	ShellLangs []string `yaml:"shellLangs"`
+	// Vocabulary is a file replacing vocabulary.yaml, relative to the
+	// configuration file.
+	Vocabulary string `yaml:"vocabulary"`
+}
+
+// findConfig returns the path of the nearest .styleguide.yaml in dir or one of
+// its parents, or "" if there is none.
+func findConfig(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		p := filepath.Join(dir, configFileName)
+		if _, err := os.Stat(p); err == nil {
+			return p
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// loadConfig reads and validates a configuration file.
+func loadConfig(p string) (*Config, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := parseConfig(data, filepath.Dir(p))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	return cfg, nil
+}
+
+// parseConfig parses and validates the content of a configuration file in
+// directory dir.
+func parseConfig(data []byte, dir string) (*Config, error) {
+	cfg := &Config{}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	var err error
+	if cfg.dir, err = filepath.Abs(dir); err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", cfg.dir, data)
+
+	known := allRules()
+	if v := cfg.Options.Vocabulary; v != "" {
+		if !filepath.IsAbs(v) {
+			v = filepath.Join(cfg.dir, v)
+		}
+		data, err := os.ReadFile(v)
+		if err != nil {
+			return nil, fmt.Errorf("options.vocabulary: %w", err)
+		}
+		if cfg.terms, err = parseVocabulary(data); err != nil {
+			return nil, fmt.Errorf("options.vocabulary: %s: %w", v, err)
+		}
+		h.Write(data)
+		known = withTerms(cfg.terms)
+	}
+
+	if err := validateRules(cfg.Rules, known); err != nil {
+		return nil, err
+	}
+	for i, o := range cfg.Overrides {
+		if len(o.Paths) == 0 {
+			return nil, fmt.Errorf("overrides[%d]: paths is required", i)
+		}
+		for _, p := range o.Paths {
+			if _, err := path.Match(strings.ReplaceAll(p, "**", "*"), ""); err != nil {
+				return nil, fmt.Errorf("overrides[%d]: invalid path %q", i, p)
+			}
+		}
+		if err := validateRules(o.Rules, known); err != nil {
+			return nil, fmt.Errorf("overrides[%d]: %w", i, err)
+		}
+	}
+	cfg.hash = hex.EncodeToString(h.Sum(nil))
+	return cfg, nil
+}
+
+// fingerprint identifies the configuration in cache keys; "" is no
+// configuration.
+func (c *Config) fingerprint() string {
+	if c == nil {
+		return ""
+	}
+	return c.hash
+}
+
+// validateRules checks that every key names at least one of the known rules
+// and every value is a severity.
+func validateRules(m map[string]string, known []Rule) error {
+	for key, sev := range m {
+		switch sev {
+		case severityOff, severityWarning, severityError:
+		default:
+			return fmt.Errorf("rule %s: severity must be %s, %s or %s, not %q", key, severityOff, severityWarning, severityError, sev)
+		}
+		if _, err := path.Match(key, ""); err != nil {
+			return fmt.Errorf("rule %s: invalid pattern", key)
+		}
+		if !knownRulePattern(key, known) {
+			return fmt.Errorf("rule %s: no such rule", key)
+		}
+	}
+	return nil
+}
+
+// applyOptions replaces the built-in rule settings with the configured ones.
+func (c *Config) applyOptions() {
+	if c == nil {
+		return
+	}
+	if c.Options.BadLinkText != nil {
+		badLinkText = map[string]bool{}
+		for _, t := range c.Options.BadLinkText {
+			badLinkText[strings.ToLower(strings.TrimSpace(t))] = true
+		}
+	}
+	if c.terms != nil {
+		termRules = c.terms
+	}
+	if c.Options.ShellLangs != nil {
+		shellLangs = map[string]bool{}
+		for _, l := range c.Options.ShellLangs {
+			shellLangs[l] = true
+		}
+	}
+}
+
+// apply sets the configured level of every finding and drops the findings of
+// disabled rules. A nil configuration keeps the findings as they are.
+func (c *Config) apply(findings []Finding) []Finding {
+	if c == nil {
+		return findings
+	}
+	out := findings[:0]
+	for _, f := range findings {
+		switch c.severity(f.File, f.Rule) {
+		case severityOff:
+			continue
+		case severityWarning:
+			f.Level = Warning
+		case severityError:
+			f.Level = ErrorLevel
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// enabled reports whether a rule reports findings for file.
+func (c *Config) enabled(file, rule string) bool {
+	return c == nil || c.severity(file, rule) != severityOff
+}
+
+// severity returns the configured severity of a rule for file, or "" if the
+// configuration does not change it.
+func (c *Config) severity(file, rule string) string {
+	sev := ruleSeverity(c.Rules, rule)
+	rel := relTo(c.dir, file)
+	for _, o := range c.Overrides {
+		for _, p := range o.Paths {
+			if matchGlob(p, rel) {
+				if s := ruleSeverity(o.Rules, rule); s != "" {
+					sev = s
+				}
+				break
+			}
+		}
+	}
+	return sev
+}
+
+// ruleSeverity returns the severity m gives a rule: that of its ID, or else
+// that of the longest matching pattern.
+func ruleSeverity(m map[string]string, rule string) string {
+	if s, ok := m[rule]; ok {
+		return s
+	}
+	sev, best := "", ""
+	for key, s := range m {
+		if ok, _ := path.Match(key, rule); ok && (len(key) > len(best) || len(key) == len(best) && key > best) {
+			sev, best = s, key
+		}
+	}
+	return sev
+}
+
+// relTo returns file relative to dir with forward slashes, or file itself if
+// it is outside dir.
+func relTo(dir, file string) string {
+	abs, err := filepath.Abs(file)
+	if err == nil {
+		if rel, err := filepath.Rel(dir, abs); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(file)
+}
+
+// matchGlob reports whether a slash-separated path matches pattern, where
+// "**" matches any number of path segments and the other segments are
+// path.Match patterns.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
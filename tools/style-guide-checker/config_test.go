@@ -0,0 +1,155 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseConfigErrors(t *testing.T) {
+	for _, tc := range []struct{ name, yaml, want string }{
+		{"unknown rule", "rules:\n  headings/title-case: off\n", "no such rule"},
+		{"unknown pattern", "rules:\n  tables/*: off\n", "no such rule"},
+		{"bad severity", "rules:\n  code/sed: fatal\n", "severity must be"},
+		{"unknown field", "rule:\n  code/sed: off\n", "field rule not found"},
+		{"override without paths", "overrides:\n  - rules:\n      code/sed: off\n", "paths is required"},
+		{"override rule", "overrides:\n  - paths: [a]\n    rules:\n      nope: off\n", "overrides[0]: rule nope"},
+	} {
+		_, err := parseConfig([]byte(tc.yaml), ".")
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: got error %v, want %q", tc.name, err, tc.want)
+		}
+	}
+	if _, err := parseConfig(nil, "."); err != nil {
+		t.Errorf("empty file: %v", err)
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"**/reference/**", "public/talos/v1.14/reference/cli.mdx", true},
+		{"**/reference/**", "reference/cli.mdx", true},
+		{"**/reference/**", "public/guides/references.mdx", false},
+		{"public/*/reference/cli.mdx", "public/omni/reference/cli.mdx", true},
+		{"public/*/reference/cli.mdx", "public/talos/v1.14/reference/cli.mdx", false},
+		{"**/*.mdx", "a.mdx", true},
+	} {
+		if got := matchGlob(tc.pattern, tc.name); got != tc.want {
+			t.Errorf("matchGlob(%q, %q) = %v, want %v", tc.pattern, tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestConfigSeverity(t *testing.T) {
+	cfg, err := parseConfig([]byte(`
+rules:
+  headings/*: error
+  headings/stacked: warning
+  code/sed: off
+overrides:
+  - paths: ["**/reference/**"]
+    rules:
+      headings/*: off
+  - paths: ["**/reference/cli.mdx"]
+    rules:
+      headings/sentence-case: warning
+`), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct{ file, rule, want string }{
+		{"guide.mdx", "headings/sentence-case", severityError},
+		{"guide.mdx", "headings/stacked", severityWarning},
+		{"guide.mdx", "code/sed", severityOff},
+		{"guide.mdx", "links/non-descriptive", ""},
+		{"reference/api.mdx", "headings/stacked", severityOff},
+		{"reference/cli.mdx", "headings/sentence-case", severityWarning},
+		{"reference/cli.mdx", "headings/skipped-level", severityOff},
+	} {
+		if got := cfg.severity(tc.file, tc.rule); got != tc.want {
+			t.Errorf("severity(%s, %s) = %q, want %q", tc.file, tc.rule, got, tc.want)
+		}
+	}
+
+	findings := []Finding{
+		{"guide.mdx", 1, Warning, "headings/sentence-case", "m", 0, 0},
+		{"guide.mdx", 2, Warning, "code/sed", "m", 0, 0},
+		{"guide.mdx", 3, ErrorLevel, "code/shell-prompt", "m", 0, 0},
+		{"reference/api.mdx", 4, Warning, "headings/stacked", "m", 0, 0},
+	}
+	got := cfg.apply(findings)
+	if len(got) != 2 || got[0].Level != ErrorLevel || got[1].Rule != "code/shell-prompt" || got[1].Level != ErrorLevel {
+		t.Errorf("apply: got %+v", got)
+	}
+	if cfg.enabled("reference/api.mdx", "headings/blank-line") {
+		t.Error("headings/blank-line enabled under reference/")
+	}
+}
+
+func TestConfigFixRespectsDisabledRules(t *testing.T) {
+	cfg, err := parseConfig([]byte("rules:\n  headings/sentence-case: off\n"), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, _ := fixContent("t.mdx", "# Getting Started\n\nText.\n", cfg)
+	if got != "## Getting Started\n\nText.\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestConfigOptions(t *testing.T) {
+	oldLinks, oldLangs := badLinkText, shellLangs
+	defer func() { badLinkText, shellLangs = oldLinks, oldLangs }()
+
+	cfg, err := parseConfig([]byte("options:\n  badLinkText: [Docs]\n  shellLangs: [bash]\n"), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.applyOptions()
+	got := lint("t.mdx", "See the [docs](/a) or [here](/b).\n\n```\n$ ls\n```\n\n```bash\n$ ls\n```\n")
+	if !hasRuleAtLine(got, "links/non-descriptive", 1) || len(got) != 3 {
+		t.Errorf("got %+v", got)
+	}
+	if hasRuleAtLine(got, "code/shell-prompt", 4) || !hasRuleAtLine(got, "code/shell-prompt", 8) {
+		t.Errorf("shellLangs not applied: %+v", got)
+	}
+}
+
+func TestFindConfig(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(dir, configFileName)
+	if err := os.WriteFile(want, []byte("rules:\n  code/sed: off\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := findConfig(sub); got != want {
+		t.Errorf("findConfig = %q, want %q", got, want)
+	}
+	cfg, err := loadConfig(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := relTo(cfg.dir, filepath.Join(sub, "c.mdx")); got != "a/b/c.mdx" {
+		t.Errorf("relPath = %q", got)
+	}
+}
+
+// Every rule lint reports is registered, with the level lint gives it.
+func TestRulesRegistered(t *testing.T) {
+	doc := "---\ntitle: getting started\n---\n# Top Level\n## Stacked Heading\n#### Deep\n\n```\n$ sed -i s/a/b/ f\n```\n\n[click here](/x) ![x](/img/My_Image.png)\n"
+	for _, f := range lint("t.mdx", doc) {
+		r, ok := ruleByID(f.Rule)
+		if !ok {
+			t.Errorf("rule %s is not registered", f.Rule)
+		} else if r.Level != f.Level {
+			t.Errorf("rule %s: registered as %s, reported as %s", f.Rule, r.Level, f.Level)
+		}
+	}
+}
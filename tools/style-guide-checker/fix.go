@@ -0,0 +1,275 @@
+package main
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// fixableRules are the rules whose findings have one unambiguous fix, applied
+// by -fix. Everything else needs a human and stays a finding.
+var fixableRules = map[string]bool{
+	"headings/blank-line":    true,
+	"headings/avoid-h1":      true,
+	"headings/sentence-case": true,
+	"code/shell-prompt":      true,
+}
+
+var (
+	// promptPrefixRe matches the "$ " prompt that code/shell-prompt flags,
+	// keeping the indentation in group 1.
+	promptPrefixRe = regexp.MustCompile(`^(\s*)\$\s+`)
+	// sectionNumberRe matches the number a heading may start with, as in
+	// "## 2 Configure authentication" or "### 1.1 Certificates".
+	sectionNumberRe = regexp.MustCompile(`^\d+(?:\.\d+)*[.)]?$`)
+)
+
+// fixContent applies the fixes of the fixable findings of a file and returns
+// the new content and the findings it fixed. Rules cfg disables for the file
+// are not fixed, nor are findings whose fix could be wrong: a heading with a
+// word the page's prose capitalizes mid-sentence, which is likely a proper
+// noun, and prompts in a block that also shows output. Fixes are computed from
+// a single lint of content, so applying them to the result finds nothing more
+// to fix.
+func fixContent(file, content string, cfg *Config) (string, []Finding) {
+	lines := strings.Split(content, "\n")
+	start := frontmatterEnd(lines)
+	keep := proseCapitals(lines, start)
+	prompts := promptOnlyLines(lines, start)
+
+	var fixed []Finding
+	rules := map[int]map[string]bool{}
+	for _, f := range lint(file, content) {
+		if !fixableRules[f.Rule] || !cfg.enabled(file, f.Rule) {
+			continue
+		}
+		switch f.Rule {
+		case "code/shell-prompt":
+			if !prompts[f.Line] {
+				continue
+			}
+		case "headings/sentence-case":
+			m := headingRe.FindStringSubmatch(lines[f.Line-1])
+			if m == nil {
+				continue
+			}
+			if _, ok := fixSentenceCase(m[2], keep); !ok {
+				continue
+			}
+		}
+		if rules[f.Line] == nil {
+			rules[f.Line] = map[string]bool{}
+		}
+		rules[f.Line][f.Rule] = true
+		fixed = append(fixed, f)
+	}
+	if len(fixed) == 0 {
+		return content, nil
+	}
+
+	out := make([]string, 0, len(lines)+len(fixed))
+	for i, line := range lines {
+		r := rules[i+1]
+		if r["code/shell-prompt"] {
+			out = append(out, promptPrefixRe.ReplaceAllString(line, "$1"))
+			continue
+		}
+		m := headingRe.FindStringSubmatch(line)
+		if m == nil || len(r) == 0 {
+			out = append(out, line)
+			continue
+		}
+
+		if r["headings/avoid-h1"] || r["headings/sentence-case"] {
+			level, text := len(m[1]), m[2]
+			if r["headings/avoid-h1"] {
+				level = 2
+			}
+			// A demoted H1 becomes subject to sentence case, so fix both at
+			// once, unless sentence case is off.
+			if r["headings/sentence-case"] || r["headings/avoid-h1"] && cfg.enabled(file, "headings/sentence-case") {
+				if t, ok := fixSentenceCase(text, keep); ok {
+					text = t
+				}
+			}
+			line = strings.Repeat("#", level) + " " + text
+		}
+		blank := r["headings/blank-line"]
+		if blank && i > start {
+			// The blank line goes above the suppression comments of the
+			// heading.
+			k := len(out)
+			for k > start && isSuppression(out[k-1]) {
+				k--
+			}
+			if k > start && strings.TrimSpace(out[k-1]) != "" {
+				out = append(out[:k], append([]string{""}, out[k:]...)...)
+			}
+		}
+		out = append(out, line)
+		if blank && i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "" {
+			out = append(out, "")
+		}
+	}
+	return strings.Join(out, "\n"), fixed
+}
+
+// fixSentenceCase lowercases every word of a heading that sentenceCaseIssue
+// would flag, leaving code spans and link targets alone. It reports false, and
+// the heading should be left as it is, if a flagged word is in keep or follows
+// a section number.
+func fixSentenceCase(text string, keep map[string]bool) (string, bool) {
+	b := []byte(text)
+	words := headingWords(text)
+	for i, w := range words {
+		if i == 0 {
+			continue
+		}
+		word := text[w[0]:w[1]]
+		core := coreWord(word)
+		if core == "" || !plainCapRe.MatchString(core) || exceptionSet[strings.ToLower(core)] {
+			continue
+		}
+		prev := words[i-1]
+		if endsSentence(emphasisRe.ReplaceAllString(text[prev[0]:prev[1]], "")) {
+			continue
+		}
+		if keep[core] || i == 1 && sectionNumberRe.MatchString(text[prev[0]:prev[1]]) {
+			// A likely proper noun, or the first word after a section
+			// number: a human decides.
+			return text, false
+		}
+		k := w[0] + strings.Index(word, core)
+		b[k] += 'a' - 'A'
+	}
+	return string(b), true
+}
+
+// proseCapitals returns the capitalized words the prose of a page uses in the
+// middle of a sentence, such as "Traefik" or the "Labs" of "Sidero Labs". The
+// first word of a line, list item or table cell may start a sentence and does
+// not count; neither do code blocks, headings and component tags.
+func proseCapitals(lines []string, start int) map[string]bool {
+	words := map[string]bool{}
+	fence := ""
+	for _, line := range lines[start:] {
+		trimmed := strings.TrimSpace(line)
+		if marker := fenceMarkerOf(trimmed); marker != "" {
+			if fence == "" {
+				fence = marker
+			} else if marker[0] == fence[0] && len(marker) >= len(fence) {
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" || headingRe.MatchString(line) || strings.HasPrefix(trimmed, "<") {
+			continue
+		}
+		fields := strings.Fields(stripHeadingInline(line))
+		for i := 1; i < len(fields); i++ {
+			core := coreWord(fields[i])
+			if core == "" || !plainCapRe.MatchString(core) {
+				continue
+			}
+			if prev := fields[i-1]; coreWord(prev) == "" || endsSentence(prev) {
+				continue
+			}
+			words[core] = true
+		}
+	}
+	return words
+}
+
+// promptOnlyLines returns the prompt lines of the code blocks that hold only
+// "$ " prompts and their continuations (see promptSession), whose prompts
+// code/shell-prompt can strip. A block that also shows output keeps its
+// prompts: without them the commands could not be told from the output.
+func promptOnlyLines(lines []string, start int) map[int]bool {
+	prompts := map[int]bool{}
+	fence, open := "", 0
+	for i := start; i < len(lines); i++ {
+		marker := fenceMarkerOf(strings.TrimSpace(lines[i]))
+		switch {
+		case marker == "":
+		case fence == "":
+			fence, open = marker, i
+		case marker[0] == fence[0] && len(marker) >= len(fence):
+			block := lines[open+1 : i]
+			if _, only := promptSession(block); only {
+				for j, line := range block {
+					if promptRe.MatchString(line) {
+						prompts[open+j+2] = true
+					}
+				}
+			}
+			fence = ""
+		}
+	}
+	return prompts
+}
+
+// headingWords returns the byte ranges of the words of a heading the case
+// checks look at: code spans are skipped and links count by their text.
+func headingWords(text string) [][2]int {
+	var words [][2]int
+	start := -1
+	end := func(i int) {
+		if start >= 0 {
+			words = append(words, [2]int{start, i})
+			start = -1
+		}
+	}
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '`':
+			end(i)
+			if j := strings.IndexByte(text[i+1:], '`'); j >= 0 {
+				i += j + 1
+			}
+		case c == ']' && strings.HasPrefix(text[i:], "]("):
+			end(i)
+			if j := strings.IndexByte(text[i:], ')'); j >= 0 {
+				i += j
+			}
+		case c == ' ' || c == '\t' || c == '[':
+			end(i)
+		default:
+			if start < 0 {
+				start = i
+			}
+		}
+	}
+	end(len(text))
+	return words
+}
+
+// writeFileAtomic replaces path with data through a temporary file in the same
+// directory, so a failed write never leaves a truncated page. The file keeps
+// its permissions.
+func writeFileAtomic(path string, data []byte) error {
+	mode := fs.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
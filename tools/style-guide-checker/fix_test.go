@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFixContent(t *testing.T) {
+	doc := "---\ntitle: Test\n---\n## Install The Talos CLI\nIntro.\n\n# Getting Started Now\n\nText.\n\n```bash\n$ talosctl version\n  $ kubectl get nodes\n```\n\nSee [here](https://example.com).\n"
+	want := "---\ntitle: Test\n---\n## Install the Talos CLI\n\nIntro.\n\n## Getting started now\n\nText.\n\n```bash\ntalosctl version\n  kubectl get nodes\n```\n\nSee [here](https://example.com).\n"
+	got, fixed := fixContent("t.mdx", doc, nil)
+	if got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if len(fixed) != 5 {
+		t.Errorf("expected 5 fixed findings, got %+v", fixed)
+	}
+	// Findings that need judgement are left alone.
+	if !findRule(lint("t.mdx", got), "links/non-descriptive") {
+		t.Error("non-descriptive link should still be reported")
+	}
+	again, fixed := fixContent("t.mdx", got, nil)
+	if again != got || len(fixed) != 0 {
+		t.Errorf("second fix changed the content or found %+v:\n%s", fixed, again)
+	}
+}
+
+func TestFixBlankLinesBetweenHeadings(t *testing.T) {
+	// Fixing two adjacent headings must not insert two blank lines.
+	got, _ := fixContent("t.mdx", "Intro.\n## A\n### B\nText.\n", nil)
+	if want := "Intro.\n\n## A\n\n### B\n\nText.\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if findRule(lint("t.mdx", got), "headings/blank-line") {
+		t.Errorf("blank-line findings left: %+v", lint("t.mdx", got))
+	}
+}
+
+func TestFixSentenceCase(t *testing.T) {
+	for _, tc := range []struct{ in, want string }{
+		{"Configure The `Talos Linux` Node", "Configure the `Talos Linux` node"},
+		{"Read [The Guide](https://Example.com/Docs) First", "Read [the guide](https://Example.com/Docs) first"},
+		{"**Note:** Use KubeSpan With Omni", "**Note:** Use KubeSpan with Omni"},
+		{"Step 1: Create The Cluster", "Step 1: Create the cluster"},
+	} {
+		got, ok := fixSentenceCase(tc.in, nil)
+		if !ok || got != tc.want {
+			t.Errorf("fixSentenceCase(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+		if word, bad := sentenceCaseIssue(got); bad {
+			t.Errorf("%q still flags %q", got, word)
+		}
+	}
+}
+
+func TestFixKeepsProperNouns(t *testing.T) {
+	// "Labs" and "Traefik" are capitalized mid-sentence in the prose, so the
+	// headings using them are left as findings.
+	// A word after a section number may start the sentence, so that heading
+	// is left too.
+	doc := "## Contact Sidero Labs\n\nWrite to Sidero Labs.\n\n## Install Traefik Now\n\nThen deploy Traefik.\n\n## 2 Configure Access\n\n## Read The Guide\n\nText.\n"
+	want := "## Contact Sidero Labs\n\nWrite to Sidero Labs.\n\n## Install Traefik Now\n\nThen deploy Traefik.\n\n## 2 Configure Access\n\n## Read the guide\n\nText.\n"
+	got, fixed := fixContent("t.mdx", doc, nil)
+	if got != want || len(fixed) != 1 {
+		t.Errorf("got %+v:\n%s", fixed, got)
+	}
+	if again, fixed := fixContent("t.mdx", got, nil); again != got || len(fixed) != 0 {
+		t.Errorf("second fix changed the content or found %+v", fixed)
+	}
+}
+
+func TestFixKeepsPromptsOfSessions(t *testing.T) {
+	// Prompts are only stripped from blocks of commands; a session that shows
+	// output needs them to tell the two apart.
+	doc := "```bash\n$ talosctl get members \\\n  --nodes 10.5.0.2\n\n$ talosctl version\n```\n\n" +
+		"```bash\n$ talosctl version\nClient:\n\tTag: v1.14.0\n```\n"
+	want := "```bash\ntalosctl get members \\\n  --nodes 10.5.0.2\n\ntalosctl version\n```\n\n" +
+		"```bash\n$ talosctl version\nClient:\n\tTag: v1.14.0\n```\n"
+	got, fixed := fixContent("t.mdx", doc, nil)
+	if got != want || len(fixed) != 2 {
+		t.Errorf("got %+v:\n%s", fixed, got)
+	}
+	if !findRule(lint("t.mdx", got), "code/shell-prompt") {
+		t.Error("the prompt of the session should still be reported")
+	}
+}
+
+func TestWriteFileAtomicKeepsMode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "page.mdx")
+	if err := os.WriteFile(path, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeFileAtomic(path, []byte("new")); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "new" || info.Mode().Perm() != 0o600 {
+		t.Errorf("got %q with mode %v", data, info.Mode().Perm())
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// formats are the values of -format. text and github print one finding at a
+// time; the others write one document for the whole run.
+var formats = []string{"text", "github", "json", "sarif", "checkstyle"}
+
+const toolURI = "https://github.com/siderolabs/docs/tree/main/tools/style-guide-checker"
+
+// --- JSON -------------------------------------------------------------------
+
+type jsonReport struct {
+	Findings []jsonFinding `json:"findings"`
+	Rules    []jsonRule    `json:"rules"`
+}
+
+type jsonFinding struct {
+	File      string `json:"file"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column,omitempty"`
+	EndColumn int    `json:"endColumn,omitempty"`
+	Level     string `json:"level"`
+	Rule      string `json:"rule"`
+	Message   string `json:"message"`
+}
+
+type jsonRule struct {
+	ID           string `json:"id"`
+	Description  string `json:"description"`
+	Help         string `json:"help"`
+	DefaultLevel string `json:"defaultLevel"`
+}
+
+// writeJSON writes the findings and the rule registry as one JSON object.
+func writeJSON(w io.Writer, findings []Finding) error {
+	r := jsonReport{Findings: []jsonFinding{}}
+	for _, f := range findings {
+		r.Findings = append(r.Findings, jsonFinding{
+			filepath.ToSlash(f.File), f.Line, f.Column, f.EndColumn, f.Level.String(), f.Rule, f.Message,
+		})
+	}
+	for _, rule := range allRules() {
+		r.Rules = append(r.Rules, jsonRule{rule.ID, rule.Description, rule.Help, rule.Level.String()})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// --- SARIF ------------------------------------------------------------------
+
+// The subset of SARIF 2.1.0 that code-scanning dashboards read.
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string        `json:"id"`
+	ShortDescription     sarifText     `json:"shortDescription"`
+	Help                 sarifText     `json:"help"`
+	DefaultConfiguration sarifRuleConf `json:"defaultConfiguration"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifRuleConf struct {
+	Level string `json:"level"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifText       `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifact `json:"artifactLocation"`
+	Region           sarifRegion   `json:"region"`
+}
+
+type sarifArtifact struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// writeSARIF writes the findings as a SARIF 2.1.0 log with one run, whose
+// driver lists every rule.
+func writeSARIF(w io.Writer, findings []Finding) error {
+	driver := sarifDriver{Name: "style-guide-checker", InformationURI: toolURI}
+	index := map[string]int{}
+	for i, r := range allRules() {
+		index[r.ID] = i
+		driver.Rules = append(driver.Rules, sarifRule{
+			r.ID, sarifText{r.Description}, sarifText{r.Help}, sarifRuleConf{r.Level.String()},
+		})
+	}
+	run := sarifRun{Tool: sarifTool{driver}, Results: []sarifResult{}}
+	for _, f := range findings {
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    f.Rule,
+			RuleIndex: index[f.Rule],
+			Level:     f.Level.String(),
+			Message:   sarifText{f.Message},
+			Locations: []sarifLocation{{sarifPhysicalLocation{
+				sarifArtifact{filepath.ToSlash(f.File)},
+				sarifRegion{f.Line, f.Column, f.EndColumn},
+			}}},
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
+
+// --- Checkstyle -------------------------------------------------------------
+
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// writeCheckstyle writes the findings as a checkstyle XML report, one <file>
+// per run of findings of the same file.
+func writeCheckstyle(w io.Writer, findings []Finding) error {
+	r := checkstyleReport{Version: "4.3"}
+	for _, f := range findings {
+		name := filepath.ToSlash(f.File)
+		if n := len(r.Files); n == 0 || r.Files[n-1].Name != name {
+			r.Files = append(r.Files, checkstyleFile{Name: name})
+		}
+		cf := &r.Files[len(r.Files)-1]
+		cf.Errors = append(cf.Errors, checkstyleError{f.Line, f.Column, f.Level.String(), f.Message, f.Rule})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
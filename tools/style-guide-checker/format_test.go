@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+var formatFindings = []Finding{
+	{"public/a.mdx", 3, ErrorLevel, "code/shell-prompt", "remove the prompt", 1, 2},
+	{"public/a.mdx", 9, Warning, "headings/stacked", "add a sentence", 0, 0},
+	{"public/b.mdx", 1, Warning, "links/non-descriptive", `text "here" & more`, 5, 17},
+}
+
+func TestFindingColumns(t *testing.T) {
+	doc := "---\ntitle: Install cluster\n---\n\n## Getting Started\n\nSee [here](/x) or ![a](/img/My_Pic.png).\n\n```bash\n  $ talosctl version | sed s/a/b/\n```\n"
+	want := map[string][2]int{
+		"title/title-case":       {16, 23},
+		"headings/sentence-case": {12, 19},
+		"links/non-descriptive":  {5, 15},
+		"images/filename":        {24, 39},
+		"code/shell-prompt":      {3, 4},
+		"code/sed":               {24, 27},
+	}
+	for _, f := range lint("t.mdx", doc) {
+		w, ok := want[f.Rule]
+		if !ok {
+			t.Errorf("unexpected finding %+v", f)
+			continue
+		}
+		if f.Column != w[0] || f.EndColumn != w[1] {
+			t.Errorf("%s: columns %d-%d, want %d-%d", f.Rule, f.Column, f.EndColumn, w[0], w[1])
+		}
+		delete(want, f.Rule)
+	}
+	for rule := range want {
+		t.Errorf("no %s finding", rule)
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, formatFindings); err != nil {
+		t.Fatal(err)
+	}
+	var got jsonReport
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if len(got.Findings) != 3 || got.Findings[0].Level != "error" || got.Findings[2].EndColumn != 17 {
+		t.Errorf("findings: %+v", got.Findings)
+	}
+	if strings.Contains(buf.String(), `"line": 9,
+      "column"`) {
+		t.Error("unknown column written")
+	}
+	if len(got.Rules) != len(allRules()) || got.Rules[0].Help == "" {
+		t.Errorf("rules: %+v", got.Rules)
+	}
+}
+
+func TestSARIFFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeSARIF(&buf, formatFindings); err != nil {
+		t.Fatal(err)
+	}
+	var got sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, buf.String())
+	}
+	if got.Version != "2.1.0" || len(got.Runs) != 1 {
+		t.Fatalf("log: %+v", got)
+	}
+	run := got.Runs[0]
+	for _, r := range run.Results {
+		if rule := run.Tool.Driver.Rules[r.RuleIndex]; rule.ID != r.RuleID {
+			t.Errorf("result %s points at rule %s", r.RuleID, rule.ID)
+		}
+	}
+	region := run.Results[2].Locations[0].PhysicalLocation.Region
+	if region != (sarifRegion{1, 5, 17}) {
+		t.Errorf("region: %+v", region)
+	}
+	if rule := run.Tool.Driver.Rules[run.Results[0].RuleIndex]; rule.DefaultConfiguration.Level != "error" {
+		t.Errorf("default level of %s: %q", rule.ID, rule.DefaultConfiguration.Level)
+	}
+}
+
+func TestCheckstyleFormat(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeCheckstyle(&buf, formatFindings); err != nil {
+		t.Fatal(err)
+	}
+	var got checkstyleReport
+	if err := xml.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid XML: %v\n%s", err, buf.String())
+	}
+	if len(got.Files) != 2 || len(got.Files[0].Errors) != 2 || got.Files[1].Name != "public/b.mdx" {
+		t.Fatalf("files: %+v", got.Files)
+	}
+	if e := got.Files[1].Errors[0]; e.Message != `text "here" & more` || e.Source != "links/non-descriptive" || e.Column != 5 {
+		t.Errorf("error: %+v", e)
+	}
+}
@@ -1,3 +1,8 @@
 module github.com/siderolabs/docs/style-guide-checker
 
 go 1.25.1
+
+require (
+	gopkg.in/yaml.v3 v3.0.1
+	mvdan.cc/sh/v3 v3.7.0
+)
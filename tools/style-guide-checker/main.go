@@ -7,9 +7,26 @@
 //   - Headings : sentence case; no stacked headings; no skipped levels; no body
 //     H1; blank lines around headings.
 //   - Code     : fenced blocks need a language hint; no shell prompts ($) in
-//     copy-pasteable commands; avoid `sed` (BSD/GNU differ).
-//   - Links    : no non-descriptive link text ("click here", "here", ...).
+//     copy-pasteable commands; avoid `sed` (BSD/GNU differ); yaml, json and
+//     shell blocks must parse, and line continuations must continue (see
+//     checkCodeBlock).
+//   - Links    : no non-descriptive link text ("click here", "here", ...);
+//     internal links resolve to a page and anchor of the site (see
+//     checkSiteLinks), stay in their Talos version and in the navigation.
+//   - Versions : release versions come from the variables of
+//     custom-variables.mdx of the page's version (see checkVersions).
 //   - Images   : referenced image filenames must be kebab-case.
+//   - Terms    : the wording rules of vocabulary.yaml, checked in prose.
+//
+// A finding can be ignored in the page with a
+// {/* style-ignore-next-line rule */} or {/* style-ignore-file rule */}
+// comment (see parseSuppressions); unused ones are reported. Rules can be
+// turned off or given another level per path in a .styleguide.yaml (see
+// Config). With -baseline, only findings not recorded by -write-baseline are
+// reported (see baseline). With -fix, the findings that have a single
+// mechanical fix are fixed in place (see fixContent). Files are linted in
+// parallel, and the findings of unchanged files come from a cache unless
+// -no-cache is set (see cache).
 //
 // The sentence/title-case checks consult exceptions.txt for proper nouns that
 // may stay capitalized; ALL-CAPS acronyms and CamelCase names are allowed
@@ -24,8 +41,11 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"slices"
 	"sort"
 	"strings"
+	"sync"
 )
 
 // exceptionsRaw is the editable list of proper nouns / product names that are
@@ -73,13 +93,17 @@ func (l Level) String() string {
 	return "warning"
 }
 
-// Finding is a single style-guide violation.
+// Finding is a single style-guide violation. Column and EndColumn are the
+// 1-based byte columns of the flagged text, EndColumn exclusive, or 0 when the
+// rule flags the line as a whole.
 type Finding struct {
-	File    string
-	Line    int
-	Level   Level
-	Rule    string
-	Message string
+	File      string
+	Line      int
+	Level     Level
+	Rule      string
+	Message   string
+	Column    int
+	EndColumn int
 }
 
 // --- Rule configuration -----------------------------------------------------
@@ -149,8 +173,13 @@ var (
 // --- Main -------------------------------------------------------------------
 
 func main() {
-	format := flag.String("format", "text", "output format: text or github")
+	format := flag.String("format", "text", "output format: "+strings.Join(formats, ", "))
 	strict := flag.Bool("strict", false, "exit non-zero on warnings too, not just errors")
+	configPath := flag.String("config", "", "rule configuration file (default: the nearest "+configFileName+" from the working directory up)")
+	baselinePath := flag.String("baseline", "", "baseline file: only findings not in it are reported, and fixed ones are removed from it")
+	writeBaseline := flag.String("write-baseline", "", "record the findings in a baseline file instead of reporting them")
+	fix := flag.Bool("fix", false, "fix blank lines around headings, body H1s, heading case and shell prompts in place, then report what is left")
+	noCache := flag.Bool("no-cache", false, "lint every file, instead of reusing the findings of unchanged files from the last run")
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: style-guide-checker [flags] [paths...]\n\n")
 		fmt.Fprintf(os.Stderr, "Lints .mdx documentation against the SideroLabs style guide.\n")
@@ -161,38 +190,117 @@ func main() {
 	}
 	flag.Parse()
 
+	if !slices.Contains(formats, *format) {
+		fmt.Fprintf(os.Stderr, "unknown -format %q; want one of %s\n", *format, strings.Join(formats, ", "))
+		os.Exit(2)
+	}
+
 	paths := flag.Args()
 	if len(paths) == 0 {
 		paths = []string{"public"}
 	}
 
+	if *configPath == "" {
+		*configPath = findConfig(".")
+	}
+	var cfg *Config
+	if *configPath != "" {
+		var err error
+		if cfg, err = loadConfig(*configPath); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		cfg.applyOptions()
+	}
+
+	if *baselinePath != "" && *writeBaseline != "" {
+		fmt.Fprintln(os.Stderr, "-baseline and -write-baseline are mutually exclusive")
+		os.Exit(2)
+	}
+	var bl *baseline
+	if p := *baselinePath + *writeBaseline; p != "" {
+		var err error
+		if bl, err = loadBaseline(p); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
 	files, err := collectFiles(paths)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(2)
 	}
 
+	var c *cache
+	if !*noCache {
+		c = openCache(cfg)
+	}
+	results := lintFiles(files, cfg, c, *fix)
+
 	var all []Finding
-	for _, f := range files {
-		data, err := os.ReadFile(f)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "reading %s: %v\n", f, err)
+	fixedFindings, fixedFiles, baselined, cached := 0, 0, 0, 0
+	for i, r := range results {
+		f := files[i]
+		if r.err != nil {
+			fmt.Fprintln(os.Stderr, r.err)
 			os.Exit(2)
 		}
-		all = append(all, lint(f, string(data))...)
+		for _, c := range r.fixes {
+			fmt.Fprintf(os.Stderr, "fixed %s:%d: %s\n", c.File, c.Line, c.Rule)
+		}
+		if len(r.fixes) > 0 {
+			fixedFindings += len(r.fixes)
+			fixedFiles++
+		}
+		if r.cached {
+			cached++
+		}
+		findings := r.findings
+		switch {
+		case *writeBaseline != "":
+			bl.record(f, r.content, findings)
+			findings = nil
+		case bl != nil:
+			var known int
+			findings, known = bl.filter(f, r.content, findings)
+			baselined += known
+		}
+		all = append(all, findings...)
 	}
 
-	sort.Slice(all, func(i, j int) bool {
-		if all[i].File != all[j].File {
-			return all[i].File < all[j].File
+	if bl != nil {
+		before, after := baselineSize(bl.entries), bl.result()
+		if *writeBaseline != "" || baselineSize(after) < before {
+			if err := bl.save(after); err != nil {
+				fmt.Fprintf(os.Stderr, "writing %s: %v\n", bl.path, err)
+				os.Exit(2)
+			}
 		}
-		return all[i].Line < all[j].Line
-	})
+		if *writeBaseline != "" {
+			fmt.Fprintf(os.Stderr, "Wrote %d finding(s) of %d file(s) to %s\n", baselineSize(after), len(files), bl.path)
+			return
+		}
+		if n := before - baselineSize(after); n > 0 {
+			fmt.Fprintf(os.Stderr, "Removed %d fixed finding(s) from %s\n", n, bl.path)
+		}
+	}
+
+	sortFindings(all)
 
 	warnings, errors := report(all, *format)
 
 	fmt.Fprintf(os.Stderr, "\nChecked %d file(s): %d error(s), %d warning(s)\n",
 		len(files), errors, warnings)
+	if cached > 0 {
+		fmt.Fprintf(os.Stderr, "%d file(s) unchanged since the last run; their findings came from the cache\n", cached)
+	}
+	if bl != nil {
+		fmt.Fprintf(os.Stderr, "%d finding(s) in the baseline not reported\n", baselined)
+	}
+	if *fix {
+		fmt.Fprintf(os.Stderr, "Fixed %d finding(s) in %d file(s)\n", fixedFindings, fixedFiles)
+	}
 
 	if errors > 0 || (*strict && warnings > 0) {
 		os.Exit(1)
@@ -237,41 +345,123 @@ func collectFiles(paths []string) ([]string, error) {
 	return files, nil
 }
 
+// fileResult is what checking one file produced.
+type fileResult struct {
+	content  string    // after fixes
+	findings []Finding // configured, not yet filtered by a baseline
+	fixes    []Finding // the findings -fix fixed
+	cached   bool
+	err      error
+}
+
+// lintFiles checks files on GOMAXPROCS workers, fixing them first if fix is
+// set, and returns their results in the order of files. Files whose content
+// the cache has seen are not linted again.
+func lintFiles(files []string, cfg *Config, c *cache, fix bool) []fileResult {
+	results := make([]fileResult, len(files))
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				results[i] = lintFile(files[i], cfg, c, fix)
+			}
+		}()
+	}
+	for i := range files {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return results
+}
+
+// lintFile checks one file.
+func lintFile(f string, cfg *Config, c *cache, fix bool) fileResult {
+	data, err := os.ReadFile(f)
+	if err != nil {
+		return fileResult{err: fmt.Errorf("reading %s: %w", f, err)}
+	}
+	r := fileResult{content: string(data)}
+	if fix {
+		fixed, changes := fixContent(f, r.content, cfg)
+		if len(changes) > 0 {
+			if err := writeFileAtomic(f, []byte(fixed)); err != nil {
+				return fileResult{err: fmt.Errorf("writing %s: %w", f, err)}
+			}
+			r.content, r.fixes = fixed, changes
+		}
+	}
+	if c != nil {
+		if r.findings, r.cached = c.get(f, r.content); r.cached {
+			return r
+		}
+	}
+	findings, linked := lintLinked(f, r.content)
+	r.findings = cfg.apply(findings)
+	if c != nil {
+		c.put(f, r.content, r.findings, linked)
+	}
+	return r
+}
+
+// sortFindings orders findings by file, line, column and rule, so the output
+// does not depend on the order the workers finish in.
+func sortFindings(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return a.Rule < b.Rule
+	})
+}
+
 // --- Linting ----------------------------------------------------------------
 
 // lint runs every rule over a single file's contents.
 func lint(file, content string) []Finding {
+	findings, _ := lintLinked(file, content)
+	return findings
+}
+
+// lintLinked is lint that also returns the pages of the site whose anchors
+// the links of the file were checked against.
+func lintLinked(file, content string) ([]Finding, []string) {
 	lines := strings.Split(content, "\n")
 
 	var findings []Finding
+	addAt := func(line, col, endCol int, level Level, rule, msg string) {
+		findings = append(findings, Finding{file, line, level, rule, msg, col, endCol})
+	}
 	add := func(line int, level Level, rule, msg string) {
-		findings = append(findings, Finding{file, line, level, rule, msg})
+		addAt(line, 0, 0, level, rule, msg)
 	}
 
 	// Skip a leading YAML frontmatter block so its "---" and fields are not
-	// mistaken for content. Only treat it as frontmatter if it is actually
-	// closed; an unterminated "---" is left to be parsed as ordinary content.
-	start := 0
-	if len(lines) > 0 && strings.TrimSpace(lines[0]) == "---" {
-		for i := 1; i < len(lines); i++ {
-			if strings.TrimSpace(lines[i]) == "---" {
-				start = i + 1
-				break
+	// mistaken for content.
+	start := frontmatterEnd(lines)
+	if start > 0 {
+		// Check the page title (title case) inside the closed frontmatter.
+		for i := 1; i < start-1; i++ {
+			title, ok := frontmatterTitle(lines[i])
+			if !ok {
+				continue
 			}
-		}
-		if start > 0 {
-			// Check the page title (title case) inside the closed frontmatter.
-			for i := 1; i < start-1; i++ {
-				title, ok := frontmatterTitle(lines[i])
-				if !ok {
-					continue
-				}
-				if word, bad := titleCaseIssue(title); bad {
-					add(i+1, Warning, "title/title-case",
-						fmt.Sprintf("page title should be title case; capitalize %q (or add it to exceptions.txt if it's a proper noun)", word))
-				}
-				break // a page has a single title
+			if word, bad := titleCaseIssue(title); bad {
+				col, endCol := wordColumns(lines[i], word, strings.Index(lines[i], ":")+1)
+				addAt(i+1, col, endCol, Warning, "title/title-case",
+					fmt.Sprintf("page title should be title case; capitalize %q (or add it to exceptions.txt if it's a proper noun)", word))
 			}
+			break // a page has a single title
 		}
 	}
 
@@ -286,6 +476,19 @@ func lint(file, content string) []Finding {
 	inFence := false
 	fenceMarker := ""
 	fenceLang := ""
+	var block *codeBlock
+	// inTemplate is set inside a {`...`} template literal, such as the body of
+	// a <CodeBlock>; its lines are code, not prose.
+	inTemplate := false
+
+	// Internal links are resolved against the site the file belongs to, if
+	// any.
+	site := siteOf(file)
+	page := ""
+	if site != nil {
+		page = site.pageOf(file)
+	}
+	anchored := map[string]bool{}
 
 	for i := start; i < len(lines); i++ {
 		lineNo := i + 1
@@ -298,6 +501,7 @@ func lint(file, content string) []Finding {
 				inFence = true
 				fenceMarker = marker
 				fenceLang = fenceLangOf(trimmed)
+				block = newCodeBlock(line, fenceLang, lineNo+1)
 				if fenceLang == "" {
 					add(lineNo, Warning, "code/no-language",
 						"code block has no language hint; add one (e.g. ```bash, ```json)")
@@ -310,6 +514,7 @@ func lint(file, content string) []Finding {
 			// long as the opening one (CommonMark), so a longer fence wrapping
 			// a shorter one is not closed prematurely.
 			if marker[0] == fenceMarker[0] && len(marker) >= len(fenceMarker) {
+				checkCodeBlock(addAt, block)
 				inFence = false
 				fenceMarker = ""
 				fenceLang = ""
@@ -318,12 +523,21 @@ func lint(file, content string) []Finding {
 		}
 
 		if inFence {
-			checkCodeLine(add, lineNo, line, fenceLang)
+			block.append(line)
+			checkCodeLine(addAt, lineNo, line, fenceLang)
 			continue
 		}
 
 		// --- Non-code line ---
 
+		prose := !inTemplate
+		switch {
+		case inTemplate && strings.HasPrefix(trimmed, "`}"):
+			inTemplate = false
+		case strings.HasSuffix(trimmed, "{`"):
+			inTemplate = true
+		}
+
 		if m := headingRe.FindStringSubmatch(line); m != nil {
 			level := len(m[1])
 			text := m[2]
@@ -341,8 +555,13 @@ func lint(file, content string) []Finding {
 					"avoid H1 headings in the body; the page title is the H1, so start at ## and go deeper")
 			}
 			// A heading needs a blank line before it (unless it is the first line
-			// of content) and after it.
-			if i > start && strings.TrimSpace(lines[i-1]) != "" {
+			// of content) and after it. Suppression comments directly above it
+			// belong to it.
+			prev := i - 1
+			for prev >= start && isSuppression(lines[prev]) {
+				prev--
+			}
+			if prev >= start && strings.TrimSpace(lines[prev]) != "" {
 				add(lineNo, Warning, "headings/blank-line",
 					"add a blank line before this heading")
 			}
@@ -353,64 +572,105 @@ func lint(file, content string) []Finding {
 			// Headings (H2 and deeper) should be sentence case.
 			if level >= 2 {
 				if word, bad := sentenceCaseIssue(text); bad {
-					add(lineNo, Warning, "headings/sentence-case",
+					col, endCol := wordColumns(line, word, len(m[1]))
+					addAt(lineNo, col, endCol, Warning, "headings/sentence-case",
 						fmt.Sprintf("heading should be sentence case; lowercase %q (or add it to exceptions.txt if it's a proper noun)", word))
 				}
 			}
 
+			if prose {
+				checkTerms(addAt, lineNo, line)
+			}
 			prevLevel = level
 			pendingHeadingLine = lineNo
 			continue
 		}
 
+		if isSuppression(line) {
+			continue
+		}
 		if trimmed != "" {
 			// Any real content clears the "stacked heading" watch.
 			pendingHeadingLine = 0
 		}
 
-		checkLinks(add, lineNo, line)
-		checkImages(add, lineNo, line)
+		checkLinks(addAt, lineNo, line)
+		checkImages(addAt, lineNo, line)
+		if prose {
+			checkTerms(addAt, lineNo, line)
+			if page != "" {
+				checkSiteLinks(addAt, site, page, lineNo, line, anchored)
+			}
+		}
+	}
+
+	if page != "" {
+		checkVersions(addAt, site, page, lines, start)
 	}
 
-	return findings
+	linked := make([]string, 0, len(anchored))
+	for p := range anchored {
+		linked = append(linked, p)
+	}
+	sort.Strings(linked)
+	return suppress(file, findings, parseSuppressions(lines, start)), linked
+}
+
+// frontmatterEnd returns the index of the first line after a leading YAML
+// frontmatter block, or 0 if there is none. Only a closed block counts; an
+// unterminated "---" is left to be parsed as ordinary content.
+func frontmatterEnd(lines []string) int {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return 0
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			return i + 1
+		}
+	}
+	return 0
 }
 
 // checkCodeLine applies the code rules to one line inside a fenced block.
-func checkCodeLine(add func(int, Level, string, string), lineNo int, line, lang string) {
+func checkCodeLine(add func(line, col, endCol int, level Level, rule, msg string), lineNo int, line, lang string) {
 	if !shellLangs[lang] {
 		return
 	}
 	if promptRe.MatchString(line) {
-		add(lineNo, ErrorLevel, "code/shell-prompt",
+		col := strings.IndexByte(line, '$') + 1
+		add(lineNo, col, col+1, ErrorLevel, "code/shell-prompt",
 			"remove the shell prompt \"$\" so the command can be copy-pasted")
 	}
 	// Only warn about sed in explicitly shell-tagged blocks, not un-hinted ones,
 	// to avoid false positives on arbitrary text.
-	if lang != "" && sedRe.MatchString(line) {
-		add(lineNo, Warning, "code/sed",
+	if loc := sedRe.FindStringSubmatchIndex(line); lang != "" && loc != nil {
+		col := loc[3] + 1 // after the delimiter in group 1
+		add(lineNo, col, col+len("sed"), Warning, "code/sed",
 			"avoid `sed`; its behaviour differs between BSD and GNU")
 	}
 }
 
 // checkLinks flags non-descriptive markdown link text on a line.
-func checkLinks(add func(int, Level, string, string), lineNo int, line string) {
-	for _, m := range linkRe.FindAllStringSubmatch(line, -1) {
-		isImage := m[1] == "!"
+func checkLinks(add func(line, col, endCol int, level Level, rule, msg string), lineNo int, line string) {
+	for _, loc := range linkRe.FindAllStringSubmatchIndex(line, -1) {
+		isImage := loc[3] > loc[2]
 		if isImage {
 			continue
 		}
-		text := strings.ToLower(strings.TrimSpace(emphasisRe.ReplaceAllString(m[2], "")))
+		raw := line[loc[4]:loc[5]]
+		text := strings.ToLower(strings.TrimSpace(emphasisRe.ReplaceAllString(raw, "")))
 		if badLinkText[text] {
-			add(lineNo, Warning, "links/non-descriptive",
-				fmt.Sprintf("link text %q is not descriptive; say where the link goes", strings.TrimSpace(m[2])))
+			add(lineNo, loc[0]+1, loc[1]+1, Warning, "links/non-descriptive",
+				fmt.Sprintf("link text %q is not descriptive; say where the link goes", strings.TrimSpace(raw)))
 		}
 	}
 }
 
 // checkImages flags image filenames that are not kebab-case, for both the
 // markdown ![alt](path) form and the <img src="path"> form.
-func checkImages(add func(int, Level, string, string), lineNo int, line string) {
-	check := func(path string) {
+func checkImages(add func(line, col, endCol int, level Level, rule, msg string), lineNo int, line string) {
+	// check flags path, which starts at byte start of the line.
+	check := func(path string, start int) {
 		if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
 			return
 		}
@@ -425,29 +685,44 @@ func checkImages(add func(int, Level, string, string), lineNo int, line string)
 		}
 		name := strings.TrimSuffix(base, filepath.Ext(base))
 		if !kebabRe.MatchString(name) {
-			add(lineNo, Warning, "images/filename",
+			add(lineNo, start+1, start+len(path)+1, Warning, "images/filename",
 				fmt.Sprintf("image filename %q should be kebab-case (lowercase words joined by hyphens, e.g. accessing-exposed-service%s)", base, ext))
 		}
 	}
-	for _, m := range linkRe.FindAllStringSubmatch(line, -1) {
-		if m[1] != "!" {
-			continue
+	for _, loc := range linkRe.FindAllStringSubmatchIndex(line, -1) {
+		if loc[3] == loc[2] {
+			continue // not an image
 		}
 		// Path may be followed by a title: ![alt](path "title"). Guard against a
 		// whitespace-only target like ![alt]( ), which has no fields.
-		fields := strings.Fields(m[3])
+		target := line[loc[6]:loc[7]]
+		fields := strings.Fields(target)
 		if len(fields) == 0 {
 			continue
 		}
-		check(fields[0])
+		check(fields[0], loc[6]+strings.Index(target, fields[0]))
 	}
-	for _, m := range srcRe.FindAllStringSubmatch(line, -1) {
-		check(m[1])
+	for _, loc := range srcRe.FindAllStringSubmatchIndex(line, -1) {
+		check(line[loc[2]:loc[3]], loc[2])
 	}
 }
 
 // --- Case checks ------------------------------------------------------------
 
+// wordColumns returns the columns of the first whole-word occurrence of word
+// in line at or after byte from, or 0, 0 if there is none.
+func wordColumns(line, word string, from int) (int, int) {
+	re := regexp.MustCompile(`\b` + regexp.QuoteMeta(word) + `\b`)
+	if from < 0 || from > len(line) {
+		from = 0
+	}
+	loc := re.FindStringIndex(line[from:])
+	if loc == nil {
+		return 0, 0
+	}
+	return from + loc[0] + 1, from + loc[1] + 1
+}
+
 // frontmatterTitle returns the value of a `title:` line and true if the line
 // is one. Surrounding quotes are stripped.
 func frontmatterTitle(line string) (string, bool) {
@@ -563,18 +838,34 @@ func fenceLangOf(trimmed string) string {
 // --- Reporting --------------------------------------------------------------
 
 func report(findings []Finding, format string) (warnings, errors int) {
-	var lastFile string
 	for _, f := range findings {
 		if f.Level == ErrorLevel {
 			errors++
 		} else {
 			warnings++
 		}
-		switch format {
-		case "github":
-			fmt.Printf("::%s file=%s,line=%d,title=%s::%s\n",
-				f.Level, f.File, f.Line, f.Rule, f.Message)
-		default:
+	}
+
+	var err error
+	switch format {
+	case "json":
+		err = writeJSON(os.Stdout, findings)
+	case "sarif":
+		err = writeSARIF(os.Stdout, findings)
+	case "checkstyle":
+		err = writeCheckstyle(os.Stdout, findings)
+	case "github":
+		for _, f := range findings {
+			col := ""
+			if f.Column > 0 {
+				col = fmt.Sprintf(",col=%d,endColumn=%d", f.Column, f.EndColumn)
+			}
+			fmt.Printf("::%s file=%s,line=%d%s,title=%s::%s\n",
+				f.Level, f.File, f.Line, col, f.Rule, f.Message)
+		}
+	default:
+		var lastFile string
+		for _, f := range findings {
 			if f.File != lastFile {
 				fmt.Printf("\n%s\n", f.File)
 				lastFile = f.File
@@ -582,5 +873,8 @@ func report(findings []Finding, format string) (warnings, errors int) {
 			fmt.Printf("  %d: [%s] %s: %s\n", f.Line, f.Level, f.Rule, f.Message)
 		}
 	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "writing the report: %v\n", err)
+	}
 	return warnings, errors
 }
@@ -311,7 +311,7 @@ func TestUnclosedFrontmatterDoesNotMisfire(t *testing.T) {
 }
 
 func TestGitHubFormatDoesNotPanic(t *testing.T) {
-	f := []Finding{{"a.mdx", 2, ErrorLevel, "code/shell-prompt", "msg"}}
+	f := []Finding{{"a.mdx", 2, ErrorLevel, "code/shell-prompt", "msg", 0, 0}}
 	// Just ensure report runs for both formats.
 	if w, e := report(f, "github"); e != 1 || w != 0 {
 		t.Fatalf("unexpected counts w=%d e=%d", w, e)
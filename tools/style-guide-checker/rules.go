@@ -0,0 +1,84 @@
+package main
+
+// Rule describes one check: its stable ID, the level its findings have unless
+// the configuration says otherwise, what it is about and how to fix a finding.
+type Rule struct {
+	ID          string
+	Level       Level
+	Description string
+	Help        string
+}
+
+// rules lists the built-in rules, in the order of the README. The terms/*
+// rules come from the vocabulary (see termRules).
+var rules = []Rule{
+	{"title/title-case", Warning, "The page title should be title case.",
+		"Capitalize every significant word of the frontmatter title. Add a proper noun that must stay lowercase to exceptions.txt."},
+	{"headings/sentence-case", Warning, "Headings should be sentence case.",
+		"Capitalize only the first word and proper nouns. Add a proper noun to exceptions.txt, or ignore the heading with a style-ignore-next-line comment."},
+	{"headings/stacked", Warning, "A heading should not directly follow another heading.",
+		"Add at least one sentence introducing the section between the two headings."},
+	{"headings/skipped-level", Warning, "Heading levels should step down one level at a time.",
+		"Use the next level down, for example ### under ##, so the outline has no gaps."},
+	{"headings/avoid-h1", Warning, "The page title is the H1; body headings start at ##.",
+		"Demote the heading to ## (or deeper). -fix does this."},
+	{"headings/blank-line", Warning, "Headings should be surrounded by blank lines.",
+		"Add a blank line before and after the heading. -fix does this."},
+	{"code/no-language", Warning, "Fenced code blocks need a language hint.",
+		"Add the language after the opening fence, for example ```bash or ```yaml; use ```text for plain output."},
+	{"code/shell-prompt", ErrorLevel, "Commands should not start with a \"$\" prompt, so they can be copy-pasted.",
+		"Remove the leading \"$ \". -fix does this. Show output in a separate block."},
+	{"code/sed", Warning, "Avoid sed; its behaviour differs between BSD and GNU.",
+		"Describe the edit, or use a tool that behaves the same everywhere, such as yq for YAML."},
+	{"code/yaml-syntax", ErrorLevel, "YAML blocks should parse.",
+		"Fix the YAML at the line of the finding; each document of a multi-document block is checked. Write elided lines as \"...\" on their own, or tag a shell command that writes YAML as ```bash."},
+	{"code/json-syntax", ErrorLevel, "JSON blocks should parse.",
+		"Fix the JSON at the line of the finding, or tag a fragment of a larger document as ```text. Blocks with elided (\"...\") lines are not checked."},
+	{"code/shell-syntax", Warning, "Shell blocks should be valid shell, so they can be copy-pasted.",
+		"Close the quote, finish the pipe or remove the line continuation. Show command output in a separate ```text block. Placeholders like <node-ip> are fine."},
+	{"code/continuation", ErrorLevel, "A line continuation should be followed by the rest of the command.",
+		"Remove the blank line after the \"\\\", or the \"\\\" at the end of the last line."},
+	{"links/non-descriptive", Warning, "Link text should say where the link goes.",
+		"Replace text like \"click here\" with the title or subject of the target page."},
+	{"links/missing-page", ErrorLevel, "Internal links should point at a page that exists.",
+		"Fix the path of the link. Paths resolve against the directory of docs.json (/talos/...) or the linking page (../page), with redirects applied."},
+	{"links/missing-anchor", Warning, "Internal links should point at a heading or anchor that exists on the target page.",
+		"Use the slug of a heading of the target page: lowercase, punctuation dropped, spaces turned into hyphens."},
+	{"links/cross-version", Warning, "A Talos page should link to pages of its own version.",
+		"Change the version in the link path to the version of the linking page."},
+	{"links/not-in-nav", Warning, "Pages in the navigation should link only to pages that are in it too.",
+		"Add the target page to the navigation in docs.json, or link to a page that is in it."},
+	{"versions/hard-coded", Warning, "Release versions should come from the variables of /snippets/custom-variables.mdx.",
+		"Import the variable the message names and write {variable} instead of the version, or ${variable} inside a {`...`} template literal."},
+	{"versions/wrong-import", Warning, "A Talos page should import the variables of its own version.",
+		"Import the variable with the suffix of the page's version folder, such as release_v1_13 in talos/v1.13."},
+	{"images/filename", Warning, "Image filenames should be kebab-case.",
+		"Rename the image to lowercase words joined by hyphens, such as accessing-exposed-service.png, and update its references."},
+	{"suppressions/unused", Warning, "A style-ignore comment should name a rule and silence a finding.",
+		"Remove the comment, or fix the rule it names."},
+}
+
+// allRules returns the built-in rules followed by the terms/* rules of
+// termRules.
+func allRules() []Rule {
+	return withTerms(termRules)
+}
+
+// withTerms returns the built-in rules followed by the given terms/* rules.
+func withTerms(terms []termRule) []Rule {
+	all := append([]Rule(nil), rules...)
+	for _, tr := range terms {
+		all = append(all, tr.Rule)
+	}
+	return all
+}
+
+// ruleByID returns the rule with the given ID.
+func ruleByID(id string) (Rule, bool) {
+	for _, r := range allRules() {
+		if r.ID == id {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// siteConfigName is the Mintlify configuration at the root of the docs site.
+// Its directory is the root that site paths like /talos/... resolve against.
+const siteConfigName = "docs.json"
+
+var (
+	// hrefRe matches a quoted href attribute; {`...`} expressions are skipped.
+	hrefRe = regexp.MustCompile(`\bhref\s*=\s*["']([^"']+)["']`)
+	// anchorAttrRe matches an explicit anchor: <a name="x"> or an id="x".
+	anchorAttrRe = regexp.MustCompile(`\b(?:name|id)\s*=\s*["']([^"']+)["']`)
+	// schemeRe matches a link with a scheme, such as https: or mailto:.
+	schemeRe = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.-]*:`)
+	// talosVersionRe matches the version of a Talos page path.
+	talosVersionRe = regexp.MustCompile(`^talos/(v\d+\.\d+)(?:/|$)`)
+)
+
+// A site is the docs tree under a docs.json: its pages, their anchors, the
+// pages of its navigation, its redirects and its version variables.
+type site struct {
+	root      string
+	pages     map[string]string // site path (no leading "/", no ".mdx") -> file
+	nav       map[string]bool
+	redirects []redirect
+	variables []variable
+
+	mu      sync.Mutex
+	anchors map[string]map[string]bool // site path -> anchors, read on demand
+
+	fingerprintOnce sync.Once
+	fingerprintHash string
+}
+
+type redirect struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+}
+
+var sites = struct {
+	sync.Mutex
+	byDir map[string]*site // directory -> its site, or nil if it has none
+}{byDir: map[string]*site{}}
+
+// siteOf returns the site a file belongs to, or nil if no parent directory
+// has a docs.json.
+func siteOf(file string) *site {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil
+	}
+	sites.Lock()
+	defer sites.Unlock()
+
+	var visited []string
+	for dir := filepath.Dir(abs); ; dir = filepath.Dir(dir) {
+		s, ok := sites.byDir[dir]
+		if !ok {
+			if _, err := os.Stat(filepath.Join(dir, siteConfigName)); err == nil {
+				s, ok = loadSite(dir), true
+			}
+		}
+		if ok || filepath.Dir(dir) == dir {
+			for _, d := range append(visited, dir) {
+				sites.byDir[d] = s
+			}
+			return s
+		}
+		visited = append(visited, dir)
+	}
+}
+
+// loadSite indexes the pages under root and reads its docs.json. A site whose
+// docs.json cannot be read is still indexed, without nav or redirects.
+func loadSite(root string) *site {
+	s := &site{root: root, pages: map[string]string{}, nav: map[string]bool{}, anchors: map[string]map[string]bool{}}
+	s.variables = loadVariables(root)
+	_ = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(p, ".mdx") {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return nil
+		}
+		page := strings.TrimSuffix(filepath.ToSlash(rel), ".mdx")
+		s.pages[page] = p
+		if base := path.Base(page); base == "index" {
+			s.pages[path.Dir(page)] = p
+		}
+		return nil
+	})
+
+	data, err := os.ReadFile(filepath.Join(root, siteConfigName))
+	if err != nil {
+		return s
+	}
+	var cfg struct {
+		Navigation any        `json:"navigation"`
+		Redirects  []redirect `json:"redirects"`
+	}
+	if json.Unmarshal(data, &cfg) != nil {
+		return s
+	}
+	s.redirects = cfg.Redirects
+	collectNavPages(cfg.Navigation, false, s.nav)
+	return s
+}
+
+// collectNavPages adds the strings of every "pages" list of a docs.json
+// navigation to nav.
+func collectNavPages(v any, inPages bool, nav map[string]bool) {
+	switch v := v.(type) {
+	case string:
+		if inPages {
+			nav[strings.Trim(v, "/")] = true
+		}
+	case []any:
+		for _, e := range v {
+			collectNavPages(e, inPages, nav)
+		}
+	case map[string]any:
+		for k, e := range v {
+			collectNavPages(e, k == "pages", nav)
+		}
+	}
+}
+
+// pageOf returns the site path of a file, or "" if it is not a page of s.
+func (s *site) pageOf(file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return ""
+	}
+	rel, err := filepath.Rel(s.root, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return ""
+	}
+	return strings.TrimSuffix(filepath.ToSlash(rel), ".mdx")
+}
+
+// redirect returns where a site path redirects to, if it does.
+func (s *site) redirect(p string) (string, bool) {
+	for _, r := range s.redirects {
+		src := strings.Trim(r.Source, "/")
+		dst := strings.Trim(r.Destination, "/")
+		if i := strings.Index(src, ":"); i >= 0 && strings.HasSuffix(src, "*") {
+			// A wildcard such as /talos/latest/:slug*.
+			prefix := src[:i]
+			if strings.HasPrefix(p, prefix) {
+				rest := strings.TrimPrefix(p, prefix)
+				if j := strings.Index(dst, ":"); j >= 0 {
+					return dst[:j] + rest, true
+				}
+				return dst, true
+			}
+			continue
+		}
+		if p == src {
+			return dst, true
+		}
+	}
+	return "", false
+}
+
+// pageAnchors returns the anchors of a page: #top, its heading slugs and
+// explicit name and id attributes. Like Mintlify, the second heading with a
+// slug gets the slug plus "-1", the third "-2" and so on.
+func (s *site) pageAnchors(page string) map[string]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a, ok := s.anchors[page]; ok {
+		return a
+	}
+	a := map[string]bool{"top": true}
+	seen := map[string]int{}
+	if data, err := os.ReadFile(s.pages[page]); err == nil {
+		lines := strings.Split(string(data), "\n")
+		fence, inTemplate := "", false
+		for _, line := range lines[frontmatterEnd(lines):] {
+			trimmed := strings.TrimSpace(line)
+			if marker := fenceMarkerOf(trimmed); marker != "" {
+				if fence == "" {
+					fence = marker
+				} else if marker[0] == fence[0] && len(marker) >= len(fence) {
+					fence = ""
+				}
+				continue
+			}
+			switch {
+			case fence != "":
+				continue
+			case inTemplate:
+				inTemplate = !strings.HasPrefix(trimmed, "`}")
+				continue
+			case strings.HasSuffix(trimmed, "{`"):
+				inTemplate = true
+			}
+			if m := headingRe.FindStringSubmatch(line); m != nil {
+				slug := headingSlug(m[2])
+				if n := seen[slug]; n > 0 {
+					a[fmt.Sprintf("%s-%d", slug, n)] = true
+				} else {
+					a[slug] = true
+				}
+				seen[slug]++
+			}
+			for _, m := range anchorAttrRe.FindAllStringSubmatch(line, -1) {
+				a[strings.ToLower(m[1])] = true
+			}
+		}
+	}
+	s.anchors[page] = a
+	return a
+}
+
+// headingSlug returns the anchor Mintlify gives a heading: the text without
+// Markdown, lowercased, with punctuation other than "-" and "_" dropped and
+// spaces turned into hyphens.
+func headingSlug(text string) string {
+	text = mdLinkTextRe.ReplaceAllString(text, "$1")
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteByte('-')
+		}
+	}
+	return b.String()
+}
+
+// checkSiteLinks resolves the internal links of one line of page against its
+// site, adding the pages whose anchors it looks up to anchored. Links in
+// snippets are only checked when they are site-absolute, since relative ones
+// resolve against the page that imports the snippet.
+func checkSiteLinks(add func(line, col, endCol int, level Level, rule, msg string), s *site, page string, lineNo int, line string, anchored map[string]bool) {
+	masked := inlineCodeRe.ReplaceAllStringFunc(line, func(c string) string {
+		return strings.Repeat(" ", len(c))
+	})
+	var targets [][2]int
+	for _, loc := range linkRe.FindAllStringSubmatchIndex(masked, -1) {
+		if loc[3] > loc[2] {
+			continue // an image
+		}
+		target := masked[loc[6]:loc[7]]
+		fields := strings.Fields(target)
+		if len(fields) == 0 {
+			continue
+		}
+		start := loc[6] + strings.Index(target, fields[0])
+		targets = append(targets, [2]int{start, start + len(fields[0])})
+	}
+	for _, loc := range hrefRe.FindAllStringSubmatchIndex(masked, -1) {
+		targets = append(targets, [2]int{loc[2], loc[3]})
+	}
+
+	snippet := strings.HasPrefix(page, "snippets/")
+	for _, t := range targets {
+		target := line[t[0]:t[1]]
+		if schemeRe.MatchString(target) || strings.HasPrefix(target, "//") {
+			continue
+		}
+		if snippet && !strings.HasPrefix(target, "/") {
+			continue
+		}
+		if id, msg := s.checkLink(page, target, anchored); id != "" {
+			r, _ := ruleByID(id)
+			add(lineNo, t[0]+1, t[1]+1, r.Level, id, msg)
+		}
+	}
+}
+
+// checkLink returns the rule a link from page breaks and why, or "" if the
+// link is fine. A target page whose anchors it looks up is added to anchored.
+func (s *site) checkLink(page, target string, anchored map[string]bool) (rule, msg string) {
+	p, anchor, _ := strings.Cut(target, "#")
+	p, _, _ = strings.Cut(p, "?")
+	if a, err := url.PathUnescape(anchor); err == nil {
+		anchor = a
+	}
+	switch {
+	case p == "":
+		p = page
+	case strings.HasPrefix(p, "/"):
+		p = strings.Trim(path.Clean(p), "/")
+	default:
+		p = strings.Trim(path.Join(path.Dir(page), p), "/")
+	}
+	p = strings.TrimSuffix(strings.TrimSuffix(p, ".mdx"), ".md")
+	if ext := path.Ext(p); ext != "" && !strings.Contains(ext, " ") && !talosVersionRe.MatchString(p+"/") {
+		return "", "" // a static file, not a page
+	}
+
+	if from, to := talosVersion(page), talosVersion(p); from != "" && to != "" && from != to {
+		return "links/cross-version", fmt.Sprintf("link from Talos %s into Talos %s (/%s); link to the page of the same version", from, to, p)
+	}
+	if dst, ok := s.redirect(p); ok {
+		p = dst
+	}
+	if _, ok := s.pages[p]; !ok {
+		return "links/missing-page", fmt.Sprintf("link target /%s does not exist", p)
+	}
+	if anchor != "" {
+		anchored[p] = true
+		if !s.pageAnchors(p)[strings.ToLower(anchor)] {
+			return "links/missing-anchor", fmt.Sprintf("/%s has no heading or anchor #%s", p, anchor)
+		}
+	}
+	if s.nav[page] && !s.nav[p] && p != page {
+		return "links/not-in-nav", fmt.Sprintf("/%s is not in the navigation of %s; readers cannot find it from there", p, siteConfigName)
+	}
+	return "", ""
+}
+
+// talosVersion returns the version of a Talos site path, or "".
+func talosVersion(p string) string {
+	if m := talosVersionRe.FindStringSubmatch(p); m != nil {
+		return m[1]
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSite writes a docs.json and the given pages (site path -> content)
+// into a temporary directory and returns it.
+func writeSite(t *testing.T, pages map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	docs := `{
+  "navigation": {"tabs": [{"tab": "Talos", "groups": [{"group": "Start", "pages": [
+    "talos/v1.1/a", "talos/v1.1/b", {"group": "Nested", "pages": ["talos/v1.1/guides/c"]}
+  ]}]}]},
+  "redirects": [
+    {"source": "/talos/latest/:slug*", "destination": "/talos/v1.1/:slug*"},
+    {"source": "/old", "destination": "/talos/v1.1/b"}
+  ]
+}`
+	files := map[string]string{siteConfigName: docs}
+	for p, content := range pages {
+		files[p+".mdx"] = content
+	}
+	for p, content := range files {
+		full := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestSiteLinks(t *testing.T) {
+	doc := "[ok](/talos/v1.1/b#step-5-generate-secrets)\n" +
+		"[ok](b.mdx#legacy) and [ok](./guides/c) and [ok](/talos/latest/b) and [ok](/old)\n" +
+		"[ok](#own) <Card href=\"https://example.com\" /> [ok](mailto:a@b.c) `[code](/nope)`\n" +
+		"[page](/talos/v1.1/nope)\n" +
+		"[anchor](/talos/v1.1/b#not-a-heading)\n" +
+		"[encoded](/talos/v1.1/b#step-5%3A-generate-secrets)\n" +
+		"[ok](/talos/v1.1/b#top) and [ok](/talos/v1.1/b#example-1) [repeat](/talos/v1.1/b#example-2)\n" +
+		"[version](../v1.2/a)\n" +
+		"<Card href=\"/talos/v1.1/hidden\" />\n" +
+		"```text\n[not a link](/nope)\n```\n\n" +
+		"<a id=\"own\"></a>\n"
+	root := writeSite(t, map[string]string{
+		"talos/v1.1/a":        doc,
+		"talos/v1.1/b":        "## Step 5: Generate secrets\n\n<a name=\"legacy\"></a>\n\n```yaml\n# not-a-heading\n```\n\n### Example\n\n### Example\n",
+		"talos/v1.1/guides/c": "Nested page.\n",
+		"talos/v1.1/hidden":   "Not in the nav.\n",
+		"talos/v1.2/a":        "Newer page.\n",
+	})
+	file := filepath.Join(root, "talos", "v1.1", "a.mdx")
+
+	var got []string
+	for _, f := range lint(file, doc) {
+		if f.Rule == "links/non-descriptive" {
+			continue
+		}
+		line := strings.Split(doc, "\n")[f.Line-1]
+		got = append(got, f.Rule+" "+line[f.Column-1:f.EndColumn-1])
+	}
+	want := []string{
+		"links/missing-page /talos/v1.1/nope",
+		"links/missing-anchor /talos/v1.1/b#not-a-heading",
+		"links/missing-anchor /talos/v1.1/b#step-5%3A-generate-secrets",
+		"links/missing-anchor /talos/v1.1/b#example-2",
+		"links/cross-version ../v1.2/a",
+		"links/not-in-nav /talos/v1.1/hidden",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSiteLinksOutsideNav(t *testing.T) {
+	root := writeSite(t, map[string]string{
+		"talos/v1.1/hidden": "[other](/talos/v1.1/draft)\n",
+		"talos/v1.1/draft":  "Draft.\n",
+		"snippets/note":     "[relative](../nope) and [absolute](/nope)\n",
+	})
+	// A page outside the nav may link to another one.
+	if fs := lint(filepath.Join(root, "talos", "v1.1", "hidden.mdx"), "[other](/talos/v1.1/draft)\n"); len(fs) != 0 {
+		t.Errorf("got %+v", fs)
+	}
+	// Relative links of a snippet resolve against the page that imports it.
+	fs := lint(filepath.Join(root, "snippets", "note.mdx"), "[relative](../nope) and [absolute](/nope)\n")
+	if len(fs) != 1 || fs[0].Rule != "links/missing-page" || fs[0].Column != 36 {
+		t.Errorf("got %+v", fs)
+	}
+}
+
+func TestSiteLinksWithoutSite(t *testing.T) {
+	if fs := lint("t.mdx", "[page](/talos/v1.1/nope)\n"); len(fs) != 0 {
+		t.Errorf("links checked without a docs.json: %+v", fs)
+	}
+}
+
+func TestHeadingSlug(t *testing.T) {
+	for heading, want := range map[string]string{
+		"Step 5: Generate Secrets Bundle": "step-5-generate-secrets-bundle",
+		"services.workloadProxy":          "servicesworkloadproxy",
+		"Use [KubeSpan](/kubespan) today": "use-kubespan-today",
+		"snake_case and kebab-case":       "snake_case-and-kebab-case",
+	} {
+		if got := headingSlug(heading); got != want {
+			t.Errorf("headingSlug(%q) = %q, want %q", heading, got, want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// suppressRe matches a suppression comment on a line of its own:
+//
+//	{/* style-ignore-next-line headings/sentence-case */}
+//	{/* style-ignore-file code/sed, code/shell-prompt */}
+//
+// Group 1 is the scope, group 2 the rules.
+var suppressRe = regexp.MustCompile(`^\s*\{/\*\s*style-ignore-(next-line|file)\b\s*(.*?)\s*\*/\}\s*$`)
+
+// A suppression silences findings of some rules on lines from..to (1-based,
+// inclusive); a file-level one covers the whole file.
+type suppression struct {
+	line     int
+	scope    string
+	rules    []string // rule IDs or path.Match patterns of them
+	from, to int
+	used     []bool // per rule
+}
+
+// isSuppression reports whether a line is a suppression comment.
+func isSuppression(line string) bool {
+	return suppressRe.MatchString(line)
+}
+
+// parseSuppressions returns the suppression comments outside code blocks. A
+// next-line suppression covers the next line that is neither blank nor another
+// suppression, or the whole code block that line opens.
+func parseSuppressions(lines []string, start int) []*suppression {
+	var sups []*suppression
+	fence := ""
+	for i := start; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if marker := fenceMarkerOf(trimmed); marker != "" {
+			if fence == "" {
+				fence = marker
+			} else if marker[0] == fence[0] && len(marker) >= len(fence) {
+				fence = ""
+			}
+			continue
+		}
+		if fence != "" {
+			continue
+		}
+		m := suppressRe.FindStringSubmatch(lines[i])
+		if m == nil {
+			continue
+		}
+		s := &suppression{line: i + 1, scope: m[1], rules: strings.FieldsFunc(m[2], func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		})}
+		s.used = make([]bool, len(s.rules))
+		if s.scope == "file" {
+			s.from, s.to = 1, len(lines)
+		} else {
+			j := i + 1
+			for j < len(lines) && (strings.TrimSpace(lines[j]) == "" || isSuppression(lines[j])) {
+				j++
+			}
+			s.from, s.to = j+1, j+1
+			if marker := fenceMarkerOf(strings.TrimSpace(lineAt(lines, j))); marker != "" {
+				for k := j + 1; k < len(lines); k++ {
+					if c := fenceMarkerOf(strings.TrimSpace(lines[k])); c != "" && c[0] == marker[0] && len(c) >= len(marker) {
+						s.to = k + 1
+						break
+					}
+				}
+			}
+		}
+		sups = append(sups, s)
+	}
+	return sups
+}
+
+func lineAt(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
+
+// suppress drops the findings a suppression covers and reports every
+// suppression rule that names no rule or silenced nothing, so stale
+// suppressions do not pile up.
+func suppress(file string, findings []Finding, sups []*suppression) []Finding {
+	if len(sups) == 0 {
+		return findings
+	}
+	out := findings[:0]
+	for _, f := range findings {
+		silenced := false
+		for _, s := range sups {
+			if f.Line < s.from || f.Line > s.to {
+				continue
+			}
+			for k, r := range s.rules {
+				if ok, _ := path.Match(r, f.Rule); ok {
+					s.used[k] = true
+					silenced = true
+				}
+			}
+		}
+		if !silenced {
+			out = append(out, f)
+		}
+	}
+
+	for _, s := range sups {
+		if len(s.rules) == 0 {
+			out = append(out, Finding{file, s.line, Warning, "suppressions/unused",
+				fmt.Sprintf("style-ignore-%s names no rule; list the rules to ignore", s.scope), 0, 0})
+		}
+		for k, r := range s.rules {
+			switch {
+			case !knownRulePattern(r, allRules()):
+				out = append(out, Finding{file, s.line, Warning, "suppressions/unused",
+					fmt.Sprintf("style-ignore-%s: no such rule %q", s.scope, r), 0, 0})
+			case !s.used[k]:
+				out = append(out, Finding{file, s.line, Warning, "suppressions/unused",
+					fmt.Sprintf("style-ignore-%s %s suppresses nothing; remove it", s.scope, r), 0, 0})
+			}
+		}
+	}
+	return out
+}
+
+// knownRulePattern reports whether a rule ID or pattern matches one of known.
+func knownRulePattern(p string, known []Rule) bool {
+	for _, r := range known {
+		if ok, _ := path.Match(p, r.ID); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package main
+
+import "testing"
+
+func TestSuppressNextLine(t *testing.T) {
+	doc := "---\ntitle: Test\n---\n\n" +
+		"{/* style-ignore-next-line headings/sentence-case */}\n" +
+		"## Using Machine Config\n\n" +
+		"Text.\n\n" +
+		"## Using Machine Config\n\n" +
+		"Text.\n"
+	got := lint("t.mdx", doc)
+	if hasRuleAtLine(got, "headings/sentence-case", 6) {
+		t.Errorf("suppressed finding reported: %+v", got)
+	}
+	if !hasRuleAtLine(got, "headings/sentence-case", 10) {
+		t.Errorf("finding on the other heading suppressed too: %+v", got)
+	}
+	if findRule(got, "headings/blank-line") || findRule(got, "suppressions/unused") {
+		t.Errorf("unexpected findings: %+v", got)
+	}
+}
+
+func TestSuppressCodeBlock(t *testing.T) {
+	doc := "{/* style-ignore-next-line code/shell-prompt */}\n\n```bash\n$ talosctl version\nClient:\n$ exit\n```\n\n```bash\n$ ls\n```\n"
+	got := lint("t.mdx", doc)
+	if hasRuleAtLine(got, "code/shell-prompt", 4) || hasRuleAtLine(got, "code/shell-prompt", 6) {
+		t.Errorf("prompts in the suppressed block reported: %+v", got)
+	}
+	if !hasRuleAtLine(got, "code/shell-prompt", 10) {
+		t.Errorf("prompt in the next block suppressed: %+v", got)
+	}
+}
+
+func TestSuppressFile(t *testing.T) {
+	doc := "{/* style-ignore-file code/sed, code/* */}\n\n```bash\nsed -i s/a/b/ f\n```\n\nSee [here](/x).\n"
+	got := lint("t.mdx", doc)
+	if findRule(got, "code/sed") || !findRule(got, "links/non-descriptive") {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestUnusedSuppressions(t *testing.T) {
+	doc := "{/* style-ignore-file code/sed */}\n\n" +
+		"{/* style-ignore-next-line headings/sentence-case headings/no-such-rule */}\n" +
+		"## Fine heading\n\n" +
+		"{/* style-ignore-next-line */}\n" +
+		"Text.\n\n" +
+		"```mdx\n{/* style-ignore-file code/sed */}\n```\n"
+	got := lint("t.mdx", doc)
+	var lines []int
+	for _, f := range got {
+		if f.Rule == "suppressions/unused" {
+			lines = append(lines, f.Line)
+		}
+	}
+	// code/sed, headings/sentence-case, the unknown rule and the empty one;
+	// the comment inside the code block is not a suppression.
+	want := []int{1, 3, 3, 6}
+	if len(lines) != len(want) {
+		t.Fatalf("unused suppressions on lines %v, want %v: %+v", lines, want, got)
+	}
+	for _, l := range want {
+		if !hasRuleAtLine(got, "suppressions/unused", l) {
+			t.Errorf("no unused suppression on line %d: %+v", l, got)
+		}
+	}
+}
+
+func TestFixKeepsSuppressionOnHeading(t *testing.T) {
+	doc := "Intro.\n{/* style-ignore-next-line headings/sentence-case */}\n## Using Machine Config\nText.\n"
+	got, _ := fixContent("t.mdx", doc, nil)
+	want := "Intro.\n\n{/* style-ignore-next-line headings/sentence-case */}\n## Using Machine Config\n\nText.\n"
+	if got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if again, fixed := fixContent("t.mdx", got, nil); again != got || len(fixed) != 0 {
+		t.Errorf("second fix changed the file: %+v", fixed)
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// vocabularyRaw is the default vocabulary of the terms/* rules.
+//
+//go:embed vocabulary.yaml
+var vocabularyRaw []byte
+
+// termRules are the terms/* rules checked in prose. A configuration with a
+// vocabulary option replaces them.
+var termRules = mustVocabulary(vocabularyRaw)
+
+// Vocabulary is the format of vocabulary.yaml.
+type Vocabulary struct {
+	Rules []VocabularyRule `yaml:"rules"`
+}
+
+// VocabularyRule is one terms/<ID> rule.
+type VocabularyRule struct {
+	ID          string `yaml:"id"`
+	Level       string `yaml:"level"`
+	Description string `yaml:"description"`
+	Terms       []Term `yaml:"terms"`
+}
+
+// Term is a preferred spelling (Use alone) or phrases to flag (Not or
+// Pattern, with Use as the suggested replacement).
+type Term struct {
+	Use        string   `yaml:"use"`
+	Not        []string `yaml:"not"`
+	Pattern    string   `yaml:"pattern"`
+	IgnoreCase bool     `yaml:"ignoreCase"`
+	Reason     string   `yaml:"reason"`
+}
+
+// A termRule is a compiled VocabularyRule.
+type termRule struct {
+	Rule
+	matchers []termMatcher
+}
+
+type termMatcher struct {
+	re        *regexp.Regexp
+	use       string
+	preferred bool // re matches use in any case; only other casings are flagged
+	phrase    bool // re matches a word or phrase, not a custom pattern
+	reason    string
+	// needle is a lowercased literal every match contains, or "": a line
+	// that does not contain it is ruled out without running re.
+	needle string
+}
+
+func mustVocabulary(data []byte) []termRule {
+	v, err := parseVocabulary(data)
+	if err != nil {
+		panic("vocabulary.yaml: " + err.Error())
+	}
+	return v
+}
+
+// parseVocabulary parses and compiles a vocabulary file.
+func parseVocabulary(data []byte) ([]termRule, error) {
+	var v Vocabulary
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&v); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	var out []termRule
+	seen := map[string]bool{}
+	for _, vr := range v.Rules {
+		if !kebabRe.MatchString(vr.ID) || seen[vr.ID] {
+			return nil, fmt.Errorf("rule %q: id must be unique and kebab-case", vr.ID)
+		}
+		seen[vr.ID] = true
+		tr := termRule{Rule: Rule{ID: "terms/" + vr.ID, Description: vr.Description}}
+		switch vr.Level {
+		case "", severityWarning:
+			tr.Level = Warning
+		case severityError:
+			tr.Level = ErrorLevel
+		default:
+			return nil, fmt.Errorf("rule %s: level must be %s or %s, not %q", vr.ID, severityWarning, severityError, vr.Level)
+		}
+		for i, t := range vr.Terms {
+			m, err := compileTerm(t)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: terms[%d]: %w", vr.ID, i, err)
+			}
+			tr.matchers = append(tr.matchers, m...)
+		}
+		tr.Help = termHelp(vr)
+		out = append(out, tr)
+	}
+	return out, nil
+}
+
+func compileTerm(t Term) ([]termMatcher, error) {
+	flags := ""
+	if t.IgnoreCase {
+		flags = "(?i)"
+	}
+	switch {
+	case t.Pattern != "" && len(t.Not) > 0:
+		return nil, errors.New("set either not or pattern")
+	case t.Pattern != "":
+		re, err := regexp.Compile(flags + t.Pattern)
+		if err != nil {
+			return nil, err
+		}
+		return []termMatcher{{re: re, use: t.Use, reason: t.Reason, needle: requiredLiteral(t.Pattern)}}, nil
+	case len(t.Not) > 0:
+		var ms []termMatcher
+		for _, phrase := range t.Not {
+			ms = append(ms, termMatcher{re: phraseRe(flags, phrase), use: t.Use, phrase: true, reason: t.Reason, needle: strings.ToLower(phrase)})
+		}
+		return ms, nil
+	case t.Use != "":
+		return []termMatcher{{re: phraseRe("(?i)", t.Use), use: t.Use, preferred: true, phrase: true, reason: t.Reason, needle: strings.ToLower(t.Use)}}, nil
+	}
+	return nil, errors.New("set use, not or pattern")
+}
+
+// phraseRe matches phrase as whole words.
+func phraseRe(flags, phrase string) *regexp.Regexp {
+	expr := regexp.QuoteMeta(phrase)
+	if isWordByte(phrase[0]) {
+		expr = `\b` + expr
+	}
+	if isWordByte(phrase[len(phrase)-1]) {
+		expr += `\b`
+	}
+	return regexp.MustCompile(flags + expr)
+}
+
+// requiredLiteral returns the longest literal, lowercased, that every match
+// of pattern contains, or "" if there is none.
+func requiredLiteral(pattern string) string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return ""
+	}
+	var walk func(re *syntax.Regexp) string
+	walk = func(re *syntax.Regexp) string {
+		switch re.Op {
+		case syntax.OpLiteral:
+			return strings.ToLower(string(re.Rune))
+		case syntax.OpCapture, syntax.OpPlus:
+			return walk(re.Sub[0])
+		case syntax.OpConcat:
+			longest := ""
+			for _, sub := range re.Sub {
+				if lit := walk(sub); len(lit) > len(longest) {
+					longest = lit
+				}
+			}
+			return longest
+		}
+		return ""
+	}
+	return walk(re)
+}
+
+// hyphenated reports whether s[start:end] is joined to a neighbouring word by
+// a hyphen, as "kubernetes" is in "kubernetes-sigs": it is part of a name, not
+// the term on its own.
+func hyphenated(s string, start, end int) bool {
+	return start >= 2 && s[start-1] == '-' && isWordByte(s[start-2]) ||
+		end+1 < len(s) && s[end] == '-' && isWordByte(s[end+1])
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// termHelp lists the preferred terms and replacements of a rule.
+func termHelp(vr VocabularyRule) string {
+	var parts []string
+	for _, t := range vr.Terms {
+		switch {
+		case len(t.Not) > 0 && t.Use != "":
+			parts = append(parts, fmt.Sprintf("%s: use %s", strings.Join(t.Not, ", "), t.Use))
+		case len(t.Not) > 0:
+			parts = append(parts, "avoid "+strings.Join(t.Not, ", "))
+		case t.Pattern != "" && t.Use != "":
+			parts = append(parts, "use "+t.Use)
+		case t.Use != "":
+			parts = append(parts, "write "+t.Use)
+		}
+	}
+	return strings.Join(parts, "; ") + "."
+}
+
+// proseMaskRe matches the parts of a line that are not prose: code spans, link
+// targets, URLs, paths and domains, JSX tags and expressions.
+var proseMaskRe = regexp.MustCompile("`[^`]*`" +
+	`|\]\([^)]*\)` +
+	`|<[^<>]*>` +
+	`|\{[^{}]*\}` +
+	`|[a-z][a-z0-9+.-]*://\S+` +
+	`|(?:^|[\s(])\.{0,2}/\S+|\S+/\S+/\S*` +
+	`|\b[\w-]+(?:\.[\w-]+)*\.[a-z]{2,}\b`)
+
+// moduleLineRe matches MDX import and export statements.
+var moduleLineRe = regexp.MustCompile(`^(?:import|export)\s`)
+
+// maskProse blanks out what is not prose, keeping byte offsets.
+func maskProse(line string) string {
+	return proseMaskRe.ReplaceAllStringFunc(line, func(s string) string {
+		return strings.Repeat(" ", len(s))
+	})
+}
+
+// checkTerms applies the terms/* rules to one line of prose.
+func checkTerms(add func(line, col, endCol int, level Level, rule, msg string), lineNo int, line string) {
+	if moduleLineRe.MatchString(line) {
+		return
+	}
+	// Masking only blanks text out, so a needle missing from the line is
+	// missing from its prose too.
+	lower := strings.ToLower(line)
+	var prose string
+	for _, tr := range termRules {
+		for _, m := range tr.matchers {
+			if m.needle != "" && !strings.Contains(lower, m.needle) {
+				continue
+			}
+			if prose == "" {
+				prose = maskProse(line)
+			}
+			for _, loc := range m.re.FindAllStringIndex(prose, -1) {
+				if m.phrase && hyphenated(prose, loc[0], loc[1]) {
+					continue
+				}
+				found := line[loc[0]:loc[1]]
+				var msg string
+				switch {
+				case m.preferred && found == m.use:
+					continue
+				case m.preferred:
+					msg = fmt.Sprintf("write %q, not %q", m.use, found)
+				case m.use != "":
+					msg = fmt.Sprintf("use %q instead of %q", m.use, found)
+				default:
+					msg = fmt.Sprintf("avoid %q", found)
+				}
+				if m.reason != "" {
+					msg += "; " + m.reason
+				}
+				add(lineNo, loc[0]+1, loc[1]+1, tr.Level, tr.ID, msg)
+			}
+		}
+	}
+}
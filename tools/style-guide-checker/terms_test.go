@@ -0,0 +1,126 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTermsInProse(t *testing.T) {
+	doc := "---\ntitle: Simply Install Kubernetes\n---\n\n" +
+		"## Configure kubespan\n\n" +
+		"Simply run `talosctl just` to join the whitelist, e.g. here.\n\n" +
+		"See [the kubernetes docs](https://kubernetes.io/just) or /etc/kubernetes/just.\n\n" +
+		"Clone kubernetes-sigs, a non-kubernetes project made just-in-time.\n\n" +
+		"```bash\nkubectl just works, e.g. simply\n```\n\n" +
+		"<CodeBlock lang=\"yaml\">\n{`\n# simply kubernetes\n`}\n</CodeBlock>\n\n" +
+		"<Card title=\"x\" href=\"/kubernetes/simply\" />\n"
+	var got []string
+	for _, f := range lint("t.mdx", doc) {
+		if strings.HasPrefix(f.Rule, "terms/") {
+			line := strings.Split(doc, "\n")[f.Line-1]
+			got = append(got, f.Rule+" "+line[f.Column-1:f.EndColumn-1])
+		}
+	}
+	want := []string{
+		"terms/product-names kubespan",
+		"terms/filler-words Simply",
+		"terms/latin-abbreviations e.g.",
+		"terms/inclusive-language whitelist",
+		"terms/product-names kubernetes",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestTermMessages(t *testing.T) {
+	for _, tc := range []struct{ line, rule, msg string }{
+		{"Use Talos linux.", "terms/product-names", `write "Talos Linux", not "Talos linux"`},
+		{"Use the blacklist.", "terms/inclusive-language", `use "denylist" instead of "blacklist"`},
+		{"It is easy.", "terms/filler-words", `avoid "easy"; what is easy`},
+	} {
+		fs := lint("t.mdx", tc.line+"\n")
+		if len(fs) != 1 || fs[0].Rule != tc.rule || !strings.HasPrefix(fs[0].Message, tc.msg) {
+			t.Errorf("%q: got %+v", tc.line, fs)
+		}
+	}
+	if fs := lint("t.mdx", "Talos Linux, KubeSpan and talosctl.\n"); len(fs) != 0 {
+		t.Errorf("preferred spellings flagged: %+v", fs)
+	}
+}
+
+func TestRequiredLiteral(t *testing.T) {
+	for pattern, want := range map[string]string{
+		`\be\.g\.?`:          "e.g",
+		`\betc\.`:            "etc.",
+		`(?i)\bKube(?:let)?`: "kube",
+		`(?:foo|bar)baz+`:    "ba",
+		`\d+`:                "",
+	} {
+		if got := requiredLiteral(pattern); got != want {
+			t.Errorf("requiredLiteral(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestParseVocabulary(t *testing.T) {
+	terms, err := parseVocabulary([]byte(`
+rules:
+  - id: house-style
+    level: error
+    description: House style.
+    terms:
+      - not: [utilize]
+        use: use
+      - pattern: '\bcolou?r\b'
+        ignoreCase: true
+        reason: say what changes
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(terms) != 1 || terms[0].ID != "terms/house-style" || terms[0].Level != ErrorLevel {
+		t.Fatalf("got %+v", terms)
+	}
+	if terms[0].Help != "utilize: use use." {
+		t.Errorf("help: %q", terms[0].Help)
+	}
+
+	for _, tc := range []struct{ yaml, want string }{
+		{"rules:\n  - id: Bad_ID\n    terms: [{use: x}]\n", "kebab-case"},
+		{"rules:\n  - id: a\n    level: fatal\n", "level must be"},
+		{"rules:\n  - id: a\n    terms: [{reason: x}]\n", "set use, not or pattern"},
+		{"rules:\n  - id: a\n    terms: [{pattern: '('}]\n", "terms[0]"},
+		{"rules:\n  - id: a\n    terms: [{not: [x], pattern: y}]\n", "either not or pattern"},
+	} {
+		if _, err := parseVocabulary([]byte(tc.yaml)); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%q: got error %v, want %q", tc.yaml, err, tc.want)
+		}
+	}
+}
+
+func TestConfigVocabulary(t *testing.T) {
+	old := termRules
+	defer func() { termRules = old }()
+
+	dir := t.TempDir()
+	vocab := "rules:\n  - id: house-style\n    terms:\n      - not: [utilize]\n        use: use\n"
+	if err := os.WriteFile(filepath.Join(dir, "vocab.yaml"), []byte(vocab), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := parseConfig([]byte("rules:\n  terms/house-style: error\noptions:\n  vocabulary: vocab.yaml\n"), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parseConfig([]byte("rules:\n  terms/house-style: error\n"), dir); err == nil {
+		t.Error("terms/house-style accepted without the vocabulary that defines it")
+	}
+
+	cfg.applyOptions()
+	got := cfg.apply(lint("t.mdx", "Simply utilize it.\n"))
+	if len(got) != 1 || got[0].Rule != "terms/house-style" || got[0].Level != ErrorLevel {
+		t.Errorf("got %+v", got)
+	}
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// variablesPage is the snippet, relative to the site root, whose exports hold
+// the release versions pages should use instead of literals.
+const variablesPage = "snippets/custom-variables.mdx"
+
+var (
+	// exportRe matches an exported string constant of the variables snippet.
+	exportRe = regexp.MustCompile(`^export\s+const\s+(\w+)\s*=\s*['"]([^'"]*)['"]`)
+	// variableVersionRe matches the Talos version suffix of a variable name,
+	// such as _v1_13.
+	variableVersionRe = regexp.MustCompile(`_v(\d+)_(\d+)$`)
+	// patchVersionRe matches a value that names a single release, such as
+	// v1.13.7 or 1.36.1, rather than a release series like v1.13.
+	patchVersionRe = regexp.MustCompile(`^v?\d+\.\d+\.\d+`)
+	// productRe matches the name of a product that has version variables.
+	productRe = regexp.MustCompile(`(?i)\b(Kubernetes|Omni|Factory|NVIDIA|Talos)\b`)
+	// minimumAfterRe and minimumBeforeRe match the words around a minimum
+	// requirement, as in "Omni v1.10.4 or later" or "at least Omni v1.10.4".
+	minimumAfterRe  = regexp.MustCompile(`(?i)^[*_]*\s*(?:or (?:later|newer)|and above)\b`)
+	minimumBeforeRe = regexp.MustCompile(`(?i)\bat least\s+(?:\w+\s+)?[*_]*$`)
+	// variablesImportRe matches an import from the variables snippet.
+	variablesImportRe = regexp.MustCompile(`^import\s*\{([^}]*)\}\s*from\s*['"]/?` + regexp.QuoteMeta(variablesPage) + `['"]`)
+)
+
+// A variable is an export of the variables snippet.
+type variable struct {
+	name, value string
+	// version is the Talos version the variable belongs to (v1.13 for
+	// release_v1_13), or "" for one that does not belong to a version.
+	version string
+	// alias is set for an unversioned variable that has versioned twins,
+	// such as release next to release_v1_13: it follows the latest stable
+	// release, so versioned Talos pages should not use it.
+	alias bool
+}
+
+// loadVariables reads the exports of the variables snippet of a site root.
+func loadVariables(root string) []variable {
+	data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(variablesPage)))
+	if err != nil {
+		return nil
+	}
+	var vars []variable
+	versioned := map[string]bool{}
+	for _, line := range strings.Split(string(data), "\n") {
+		m := exportRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		v := variable{name: m[1], value: m[2]}
+		if vm := variableVersionRe.FindStringSubmatch(v.name); vm != nil {
+			v.version = "v" + vm[1] + "." + vm[2]
+			versioned[strings.TrimSuffix(v.name, vm[0])] = true
+		}
+		vars = append(vars, v)
+	}
+	for i := range vars {
+		vars[i].alias = vars[i].version == "" && versioned[vars[i].name]
+	}
+	return vars
+}
+
+// variablesFor returns the variables a page should use: those of its Talos
+// version and the unversioned ones, or for a page outside the Talos versions,
+// every unversioned variable.
+func (s *site) variablesFor(page string) []variable {
+	version := talosVersion(page)
+	var out []variable
+	for _, v := range s.variables {
+		switch {
+		case version == "" && v.version == "",
+			version != "" && (v.version == version || v.version == "" && !v.alias):
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// checkVersions flags, in every line of a page after the frontmatter, release
+// versions written out that a variable of the page holds, and imports of
+// variables of another version. A version stated as a minimum ("v1.10.4 or
+// later") is not flagged.
+func checkVersions(add func(line, col, endCol int, level Level, rule, msg string), s *site, page string, lines []string, start int) {
+	if page+".mdx" == variablesPage {
+		return
+	}
+	vars := s.variablesFor(page)
+	version := talosVersion(page)
+	for i := start; i < len(lines); i++ {
+		line := lines[i]
+		if moduleLineRe.MatchString(line) {
+			if version != "" {
+				checkVariableImports(add, s, version, i+1, line)
+			}
+			continue
+		}
+		type literal struct {
+			loc [2]int
+			v   variable
+		}
+		var found []literal
+		seen := map[[2]int]bool{}
+		for _, v := range vars {
+			if !patchVersionRe.MatchString(v.value) {
+				continue
+			}
+			for _, loc := range versionLiterals(line, v.value) {
+				if seen[loc] || !sameProduct(v.name, line[:loc[0]]) {
+					continue // another variable of the same value, or another product
+				}
+				if minimumAfterRe.MatchString(line[loc[1]:]) || minimumBeforeRe.MatchString(line[:loc[0]]) {
+					continue // a minimum requirement, which does not move with releases
+				}
+				seen[loc] = true
+				found = append(found, literal{loc, v})
+			}
+		}
+		sort.Slice(found, func(a, b int) bool { return found[a].loc[0] < found[b].loc[0] })
+		for _, l := range found {
+			add(i+1, l.loc[0]+1, l.loc[1]+1, Warning, "versions/hard-coded",
+				fmt.Sprintf("hard-coded %q goes stale at the next release; import %s from /%s and use {%s}", line[l.loc[0]:l.loc[1]], l.v.name, variablesPage, l.v.name))
+		}
+	}
+}
+
+// checkVariableImports flags the variables an import line of a page of Talos
+// version takes from another version, or the unversioned aliases of the
+// latest release.
+func checkVariableImports(add func(line, col, endCol int, level Level, rule, msg string), s *site, version string, lineNo int, line string) {
+	m := variablesImportRe.FindStringSubmatchIndex(line)
+	if m == nil {
+		return
+	}
+	byName := map[string]variable{}
+	for _, v := range s.variables {
+		byName[v.name] = v
+	}
+	for _, name := range strings.Split(line[m[2]:m[3]], ",") {
+		name = strings.TrimSpace(name)
+		v, ok := byName[name]
+		if !ok || v.version == version || v.version == "" && !v.alias {
+			continue
+		}
+		want := strings.TrimSuffix(name, variableVersionRe.FindString(name)) + "_" + strings.ReplaceAll(version, ".", "_")
+		msg := fmt.Sprintf("%s is the %s release; this page is Talos %s, so import %s", name, v.version, version, want)
+		if v.alias {
+			msg = fmt.Sprintf("%s follows the latest stable release; this page is Talos %s, so import %s", name, version, want)
+		}
+		col := m[2] + strings.Index(line[m[2]:m[3]], name)
+		add(lineNo, col+1, col+len(name)+1, Warning, "versions/wrong-import", msg)
+	}
+}
+
+// versionLiterals returns the byte ranges of value in line, written on its
+// own: not part of a longer version, and with a "v" in front when value has
+// none (v1.36.1 for 1.36.1).
+func versionLiterals(line, value string) [][2]int {
+	var out [][2]int
+	for from := 0; ; {
+		i := strings.Index(line[from:], value)
+		if i < 0 {
+			return out
+		}
+		start, end := from+i, from+i+len(value)
+		from = end
+		if !strings.HasPrefix(value, "v") && start > 0 && line[start-1] == 'v' {
+			start--
+		}
+		if start > 0 && (isWordByte(line[start-1]) || line[start-1] == '.') {
+			continue
+		}
+		if end < len(line) && (isWordByte(line[end]) || line[end] == '-' ||
+			line[end] == '.' && end+1 < len(line) && isWordByte(line[end+1])) {
+			continue
+		}
+		out = append(out, [2]int{start, end})
+	}
+}
+
+// variableProducts names the product the variables with a name prefix are
+// versions of.
+var variableProducts = []struct{ prefix, product string }{
+	{"k8s_", "Kubernetes"},
+	{"omni_", "Omni"},
+	{"image_factory_", "Factory"},
+	{"nvidia_", "NVIDIA"},
+	{"release", "Talos"},
+	{"version", "Talos"},
+}
+
+// sameProduct reports whether a version after before can be the version of the
+// variable name: false when the last product before names another one, as in
+// "Talos releases starting from v1.5.0" for the Image Factory release v1.5.0.
+func sameProduct(name, before string) bool {
+	m := productRe.FindAllString(before, -1)
+	if m == nil {
+		return true
+	}
+	named := m[len(m)-1]
+	for _, p := range variableProducts {
+		if strings.HasPrefix(name, p.prefix) {
+			return strings.EqualFold(named, p.product)
+		}
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testVariables = "export const k8s_release = '1.36.1'\n" +
+	"export const image_factory_release = 'v1.5.0'\n\n" +
+	"{/* latest stable Talos release version */}\n" +
+	"export const release = 'v1.2.3'\n" +
+	"export const version = 'v1.2'\n\n" +
+	"export const release_v1_1 = 'v1.1.4'\n" +
+	"export const version_v1_1 = 'v1.1'\n" +
+	"export const release_v1_2 = \"v1.2.3\"\n"
+
+// versionFindings lints doc as the page at site path page and returns the
+// versions/* findings as "rule text: message" lines.
+func versionFindings(t *testing.T, page, doc string) []string {
+	t.Helper()
+	root := writeSite(t, map[string]string{"snippets/custom-variables": testVariables, page: doc})
+	var got []string
+	for _, f := range lint(filepath.Join(root, filepath.FromSlash(page)+".mdx"), doc) {
+		if strings.HasPrefix(f.Rule, "versions/") {
+			line := strings.Split(doc, "\n")[f.Line-1]
+			got = append(got, f.Rule+" "+line[f.Column-1:f.EndColumn-1]+": "+f.Message)
+		}
+	}
+	return got
+}
+
+func TestHardCodedVersions(t *testing.T) {
+	doc := "import { release_v1_1 } from '/snippets/custom-variables.mdx';\n\n" +
+		"Install Talos v1.1.4 on Kubernetes v1.36.1 (or 1.36.1).\n\n" +
+		"```bash\ncurl -LO https://example.com/releases/v1.1.4/talosctl\n```\n\n" +
+		"Talos v1.2.3, v1.1.40, v1.1.4-beta.0 and {release_v1_1} are fine.\n\n" +
+		"Talos supports SecureBoot from v1.5.0.\n\n" +
+		"Requires Talos v1.1.4 or later, Kubernetes **v1.36.1** and above, or at least Talos v1.1.4.\n"
+	got := versionFindings(t, "talos/v1.1/install", doc)
+	want := []string{
+		`versions/hard-coded v1.1.4: hard-coded "v1.1.4" goes stale at the next release; import release_v1_1 from /snippets/custom-variables.mdx and use {release_v1_1}`,
+		`versions/hard-coded v1.36.1: hard-coded "v1.36.1" goes stale at the next release; import k8s_release from /snippets/custom-variables.mdx and use {k8s_release}`,
+		`versions/hard-coded 1.36.1: hard-coded "1.36.1" goes stale at the next release; import k8s_release from /snippets/custom-variables.mdx and use {k8s_release}`,
+		`versions/hard-coded v1.1.4: hard-coded "v1.1.4" goes stale at the next release; import release_v1_1 from /snippets/custom-variables.mdx and use {release_v1_1}`,
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestHardCodedVersionsOutsideTalos(t *testing.T) {
+	// Pages outside the Talos versions use the variables of the latest release.
+	got := versionFindings(t, "omni/upgrade", "Upgrade to Talos v1.2.3.\n")
+	if len(got) != 1 || !strings.Contains(got[0], "use {release}") {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestWrongVersionImport(t *testing.T) {
+	doc := "import { release_v1_1, version, k8s_release, release_v1_2 } from '/snippets/custom-variables.mdx';\n"
+	got := versionFindings(t, "talos/v1.2/install", doc)
+	want := []string{
+		"versions/wrong-import release_v1_1: release_v1_1 is the v1.1 release; this page is Talos v1.2, so import release_v1_2",
+		"versions/wrong-import version: version follows the latest stable release; this page is Talos v1.2, so import version_v1_2",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if got := versionFindings(t, "omni/install", doc); len(got) != 0 {
+		t.Errorf("imports checked outside the Talos versions: %q", got)
+	}
+}